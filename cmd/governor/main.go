@@ -28,17 +28,95 @@ func main() {
 	logLevel := cli.StringP("log", "l", "info", "Log level")
 	schedulePath := cli.StringP("schedule", "s", "weekly_schedule.csv", "Path to weekly schedule CSV")
 	eventsPath := cli.StringP("events", "e", "events.json", "Path to events persistence file")
+	eventStore := cli.String("store", governor.StoreJSON, "Event storage backend: json, memory or bolt")
+	trashRetention := cli.Duration("trash-retention", governor.DefaultTrashRetention, "How long deleted events can be restored (0 = forever)")
+	strictSchedule := cli.Bool("strict-schedule", false, "Refuse to start (or reload) if the schedule CSV has any problem")
+	exceptionsPath := cli.StringP("exceptions", "x", "exceptions.json", "Path to schedule exceptions file")
+	legacyPeers := cli.StringSlice("legacy-peers", nil, "Nodes that only speak the v1 (unescaped) wire format")
+	wireProbe := cli.Duration("wire-probe", 2*time.Second, "How long to wait for a new peer's wire version answer (0 = no negotiation, v2 unless --legacy-peers)")
+	remindTo := cli.String("remind-to", "", "Node to push deadline reminders to (empty = off)")
+	remind := cli.String("remind", "24h,1h", "Default reminder offsets before a deadline")
+	classTo := cli.String("class-to", "", "Node to push class start/end notices to (empty = off)")
+	classLead := cli.Duration("class-lead", governor.DefaultClassLead, "How long before a class its start notice is sent")
+	semesterStart := cli.String("semester-start", "", "First day of the semester, YYYY.MM.DD (week 1; empty = ISO weeks)")
+	semesterEnd := cli.String("semester-end", "", "Last day of the semester, YYYY.MM.DD (empty = open-ended)")
+	weekStart := cli.String("week-start", "mon", "First day of the week, English or Russian name (mon, sun, пн, ...)")
+	timezone := cli.String("tz", "", "Time zone for dates and periods, e.g. Europe/Moscow (empty = system zone)")
+	lang := cli.String("lang", governor.LangEN, "Language of weekday names in replies: en or ru")
 	cli.Parse()
 
 	log.SetDefault(log.New(tint.NewHandler(os.Stdout, &tint.Options{
 		Level: logLevelMap[*logLevel],
 	})))
 
-	client := proto.New("GOVERNOR", *url,
-		proto.WithReconnect(5*time.Second),
-	)
+	opts := []proto.Option{proto.WithReconnect(5 * time.Second)}
+	if *wireProbe > 0 {
+		opts = append(opts, proto.WithWireNegotiation(*wireProbe))
+	}
+	for _, peer := range *legacyPeers {
+		opts = append(opts, proto.WithPeerWire(peer, proto.WireV1))
+	}
+	client := proto.New("GOVERNOR", *url, opts...)
+
+	zone := time.Local
+	if *timezone != "" {
+		loc, err := time.LoadLocation(*timezone)
+		if err != nil {
+			log.Error("Bad time zone", "tz", *timezone, "err", err)
+			os.Exit(1)
+		}
+		zone = loc
+	}
+	firstDay, ok := governor.ParseWeekday(*weekStart)
+	if !ok {
+		log.Error("Bad week start", "week-start", *weekStart)
+		os.Exit(1)
+	}
+	outLang, err := governor.ParseLang(*lang)
+	if err != nil {
+		log.Error("Bad language", "lang", *lang, "err", err)
+		os.Exit(1)
+	}
+	locale := governor.Locale{WeekStart: firstDay, Lang: outLang, Zone: zone}
 
-	gov, err := governor.New(client, *schedulePath, *eventsPath)
+	if *eventStore == governor.StoreBolt && !cli.CommandLine.Changed("events") {
+		*eventsPath = "events.db"
+	}
+	events, err := governor.OpenEventRepository(*eventStore, *eventsPath)
+	if err != nil {
+		log.Error("Failed to open event store", "store", *eventStore, "path", *eventsPath, "err", err)
+		os.Exit(1)
+	}
+
+	govOpts := []governor.Option{
+		governor.WithEventRepository(events),
+		governor.WithExceptionsFile(*exceptionsPath),
+		governor.WithStrictSchedule(*strictSchedule),
+		governor.WithTrashRetention(*trashRetention),
+		governor.WithLocale(locale),
+	}
+	if *eventStore != governor.StoreMemory {
+		govOpts = append(govOpts, governor.WithHistoryFile(*eventsPath+".history"))
+	}
+	if *remindTo != "" {
+		offsets, err := governor.ParseRemindOffsets(*remind)
+		if err != nil {
+			log.Error("Bad reminder offsets", "remind", *remind, "err", err)
+			os.Exit(1)
+		}
+		govOpts = append(govOpts, governor.WithReminders(*remindTo, offsets...))
+	}
+
+	if *classTo != "" {
+		govOpts = append(govOpts, governor.WithClassNotices(*classTo, *classLead))
+	}
+
+	govOpts = append(govOpts, governor.WithSemester(
+		dateFlag("semester-start", *semesterStart, zone),
+		dateFlag("semester-end", *semesterEnd, zone),
+	))
+
+	gov, err := governor.New(client, *schedulePath, *eventsPath, govOpts...)
 	if err != nil {
 		log.Error("Failed to init governor", "err", err)
 		os.Exit(1)
@@ -58,6 +136,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			log.Info("SIGHUP: reloading schedule")
+			gov.ReloadSchedule()
+		}
+	}()
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 	<-sig
@@ -66,3 +153,16 @@ func main() {
 	client.Close()
 	gov.Shutdown()
 }
+
+// dateFlag parses an optional YYYY.MM.DD flag value in loc, exiting on error.
+func dateFlag(name, val string, loc *time.Location) time.Time {
+	if val == "" {
+		return time.Time{}
+	}
+	t, err := governor.ParseDate(val, loc)
+	if err != nil {
+		log.Error("Bad date", "flag", name, "value", val, "err", err)
+		os.Exit(1)
+	}
+	return t
+}
@@ -0,0 +1,138 @@
+package governor
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// snapshotKeep is how many previous versions of a store file are kept,
+// as path.1 (newest) .. path.N.
+const snapshotKeep = 3
+
+// writeFileAtomic replaces path with data. The data goes to a temp file in
+// the same directory, which is fsynced and renamed over path, so a crash or
+// full disk leaves either the old file or the new one, never a torn one.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// writeSnapshot is writeFileAtomic that first keeps the current file as
+// path.1, shifting older snapshots up and dropping the oldest.
+func writeSnapshot(path string, data []byte, perm os.FileMode) error {
+	if err := rotateSnapshots(path); err != nil {
+		slog.Warn("snapshot rotation failed", "path", path, "err", err)
+	}
+	return writeFileAtomic(path, data, perm)
+}
+
+func snapshotPath(path string, n int) string { return fmt.Sprintf("%s.%d", path, n) }
+
+func rotateSnapshots(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for n := snapshotKeep - 1; n >= 1; n-- {
+		if err := os.Rename(snapshotPath(path, n), snapshotPath(path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// Hard link keeps path in place until the new version is renamed over it.
+	if err := os.Link(path, snapshotPath(path, 1)); err == nil {
+		return nil
+	}
+	return copyFile(path, snapshotPath(path, 1))
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, data, 0644)
+}
+
+// syncDir makes a rename in dir durable. Best effort: some filesystems
+// don't support fsync on directories, and the rename is already done.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// readRecover reads path and hands the bytes to parse. If parse rejects
+// them (e.g. a truncated file), snapshots are tried newest first; the first
+// that parses is restored as path, the bad file is kept as path.corrupt and
+// the recovery is logged. A missing path is not an error: parse is simply
+// not called.
+func readRecover(path string, parse func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	primaryErr := parse(data)
+	if primaryErr == nil {
+		return nil
+	}
+
+	for n := 1; n <= snapshotKeep; n++ {
+		snap := snapshotPath(path, n)
+		data, err := os.ReadFile(snap)
+		if err != nil {
+			continue
+		}
+		if err := parse(data); err != nil {
+			slog.Warn("snapshot unusable", "path", snap, "err", err)
+			continue
+		}
+		if err := os.Rename(path, path+".corrupt"); err != nil {
+			slog.Warn("could not set aside corrupt file", "path", path, "err", err)
+		}
+		if err := writeFileAtomic(path, data, 0644); err != nil {
+			slog.Error("could not restore file from snapshot", "path", path, "snapshot", snap, "err", err)
+		}
+		slog.Warn("RECOVERED from snapshot", "path", path, "snapshot", snap, "corrupt", path+".corrupt", "reason", primaryErr)
+		return nil
+	}
+	return primaryErr
+}
@@ -0,0 +1,130 @@
+package governor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteSnapshotRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	for v := 1; v <= 5; v++ {
+		if err := writeSnapshot(path, []byte(fmt.Sprintf("v%d", v)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for file, want := range map[string]string{
+		path:                  "v5",
+		snapshotPath(path, 1): "v4",
+		snapshotPath(path, 2): "v3",
+		snapshotPath(path, 3): "v2",
+	} {
+		if got := readString(t, file); got != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, want)
+		}
+	}
+	if _, err := os.Stat(snapshotPath(path, snapshotKeep+1)); !os.IsNotExist(err) {
+		t.Errorf("snapshot .%d kept: %v", snapshotKeep+1, err)
+	}
+}
+
+// writeEvents snapshots one version of the events file per list, oldest
+// first, so the last list is the live file and the first is the oldest
+// snapshot.
+func writeEvents(t *testing.T, path string, versions ...[]string) {
+	t.Helper()
+	for _, ids := range versions {
+		list := make([]Event, len(ids))
+		for i, id := range ids {
+			list[i] = Event{ID: id, Title: id}
+		}
+		data, err := json.Marshal(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeSnapshot(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listIDs(r EventRepository) []string {
+	var ids []string
+	for _, e := range r.List() {
+		ids = append(ids, e.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestEventStoreRecoversFromSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt []int // snapshots to truncate as well as the live file
+		want    []string
+	}{
+		{name: "newest snapshot", want: []string{"ev1", "ev2"}},
+		{name: "older snapshot", corrupt: []int{1}, want: []string{"ev1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "events.json")
+			writeEvents(t, path, []string{"ev1"}, []string{"ev1", "ev2"}, []string{"ev1", "ev2", "ev3"})
+
+			live := readString(t, path)
+			torn := live[:len(live)/2]
+			if err := os.WriteFile(path, []byte(torn), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range tt.corrupt {
+				if err := os.WriteFile(snapshotPath(path, n), []byte("[{"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r, err := NewJSONEventRepository(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			if got := listIDs(r); !slices.Equal(got, tt.want) {
+				t.Errorf("recovered %q, want %q", got, tt.want)
+			}
+			if got := readString(t, path+".corrupt"); got != torn {
+				t.Errorf(".corrupt = %q, want the torn file %q", got, torn)
+			}
+			var list []Event
+			if err := json.Unmarshal([]byte(readString(t, path)), &list); err != nil || len(list) != len(tt.want) {
+				t.Errorf("live file not restored: %d events, %v", len(list), err)
+			}
+		})
+	}
+}
+
+func TestEventStoreUnrecoverable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	writeEvents(t, path, []string{"ev1"}, []string{"ev1", "ev2"})
+	for _, p := range []string{path, snapshotPath(path, 1)} {
+		if err := os.WriteFile(p, []byte("[{"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewJSONEventRepository(path); err == nil {
+		t.Fatal("opened a store with no readable version")
+	}
+	if _, err := os.Stat(path + ".corrupt"); !os.IsNotExist(err) {
+		t.Errorf("corrupt file set aside without a recovery: %v", err)
+	}
+}
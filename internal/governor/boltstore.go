@@ -0,0 +1,157 @@
+package governor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltEventsBucket = []byte("events")
+
+// boltEventStore keeps each event as a JSON value keyed by ID in a bbolt
+// database. Every mutation is its own fsynced transaction, so there is no
+// snapshot or journal to manage.
+type boltEventStore struct {
+	db *bolt.DB
+}
+
+// NewBoltEventRepository opens (or creates) the bbolt database at path.
+// The file is locked while open, so only one governor can use it.
+func NewBoltEventRepository(path string) (EventRepository, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open events db %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltEventsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init events db %s: %w", path, err)
+	}
+	return &boltEventStore{db: db}, nil
+}
+
+func (s *boltEventStore) Add(e Event) (string, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltEventsBucket)
+		n, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		e.ID = fmt.Sprintf("ev%d", n)
+		return putBoltEvent(b, e)
+	})
+	if err != nil {
+		return "", fmt.Errorf("add event: %w", err)
+	}
+	return e.ID, nil
+}
+
+func (s *boltEventStore) Get(id string) (Event, bool) {
+	var e Event
+	var ok bool
+	s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltEventsBucket).Get([]byte(id))
+		ok = data != nil && json.Unmarshal(data, &e) == nil
+		return nil
+	})
+	return e, ok
+}
+
+func (s *boltEventStore) List() []Event {
+	return s.Query(func(Event) bool { return true })
+}
+
+func (s *boltEventStore) Query(match func(Event) bool) []Event {
+	var out []Event
+	s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltEventsBucket).ForEach(func(k, v []byte) error {
+			var e Event
+			if err := json.Unmarshal(v, &e); err != nil {
+				return nil // skip unreadable record
+			}
+			if match(e) {
+				out = append(out, e)
+			}
+			return nil
+		})
+	})
+	return out
+}
+
+func (s *boltEventStore) Update(id string, fn func(*Event) error) (Event, error) {
+	var e Event
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltEventsBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return ErrNoEvent
+		}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("decode event %s: %w", id, err)
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+		e.ID = id
+		return putBoltEvent(b, e)
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+func (s *boltEventStore) Delete(id string) bool {
+	var found bool
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltEventsBucket)
+		if found = b.Get([]byte(id)) != nil; !found {
+			return nil
+		}
+		return b.Delete([]byte(id))
+	})
+	if err != nil {
+		slog.Error("events db delete failed", "id", id, "err", err)
+	}
+	return found
+}
+
+func (s *boltEventStore) DeleteIf(id string, match func(Event) bool) (Event, bool) {
+	var e Event
+	var found bool
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltEventsBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("decode event %s: %w", id, err)
+		}
+		if found = match(e); !found {
+			return nil
+		}
+		return b.Delete([]byte(id))
+	})
+	if err != nil {
+		slog.Error("events db delete failed", "id", id, "err", err)
+		return Event{}, false
+	}
+	return e, found
+}
+
+func (s *boltEventStore) Close() error { return s.db.Close() }
+
+func putBoltEvent(b *bolt.Bucket, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(e.ID), data)
+}
@@ -0,0 +1,109 @@
+package governor
+
+import (
+	log "log/slog"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// classNotice is one pending class notification.
+type classNotice struct {
+	at      time.Time // when to send
+	classAt time.Time // when the class starts
+	start   bool      // true: "starting soon", false: "ended"
+	slot    Slot
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// classNotices builds the notices due in (now, until], from today's and
+// tomorrow's slots (a lead time can reach back across midnight).
+func (g *Governor) classNotices(now, until time.Time) []classNotice {
+	var out []classNotice
+	for d := 0; d < 2; d++ {
+		day := startOfDay(now).AddDate(0, 0, d)
+		for _, s := range g.slotsOn(day) {
+			start, end := s.On(day)
+			for _, n := range []classNotice{
+				{at: start.Add(-g.classLead), classAt: start, start: true, slot: s},
+				{at: end, classAt: start, start: false, slot: s},
+			} {
+				if n.at.After(now) && !n.at.After(until) {
+					out = append(out, n)
+				}
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].at.Before(out[j].at) })
+	return out
+}
+
+// classLoop fires class notices for the rest of the day, then rebuilds
+// at midnight or whenever the schedule changes.
+func (g *Governor) classLoop() {
+	defer g.wg.Done()
+	for {
+		now := g.now()
+		midnight := startOfDay(now).AddDate(0, 0, 1)
+		pending := g.classNotices(now, midnight)
+		log.Debug("class notices planned", "count", len(pending), "until", midnight.Format("2006-01-02 15:04"))
+		if !g.runClassNotices(pending, midnight) {
+			return
+		}
+	}
+}
+
+// runClassNotices sends pending notices as they come due. Returns false on
+// shutdown, true when the plan must be rebuilt (midnight or schedule change).
+func (g *Governor) runClassNotices(pending []classNotice, until time.Time) bool {
+	for {
+		next := until
+		if len(pending) > 0 {
+			next = pending[0].at
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-g.stop:
+			timer.Stop()
+			return false
+		case <-g.scheduleChanged:
+			timer.Stop()
+			return true
+		case <-timer.C:
+		}
+		if len(pending) == 0 {
+			return true
+		}
+		now := g.now()
+		for len(pending) > 0 && !pending[0].at.After(now) {
+			g.sendClassNotice(pending[0], now)
+			pending = pending[1:]
+		}
+	}
+}
+
+func (g *Governor) sendClassNotice(n classNotice, now time.Time) {
+	var err error
+	if n.start {
+		left := n.classAt.Sub(now).Round(time.Minute)
+		err = g.client.Send(g.classTo, "NEW", "CLASS", "START", n.slot.WireString(), strconv.Itoa(int(left.Minutes())))
+		log.Info("CLASS START", "to", g.classTo, "title", n.slot.Title, "location", n.slot.Location, "in", left)
+	} else {
+		err = g.client.Send(g.classTo, "NEW", "CLASS", "END", n.slot.WireString())
+		log.Info("CLASS END", "to", g.classTo, "title", n.slot.Title)
+	}
+	if err != nil {
+		log.Warn("class notice send failed", "to", g.classTo, "title", n.slot.Title, "err", err)
+	}
+}
+
+// notifyScheduleChanged makes the class loop rebuild its plan.
+func (g *Governor) notifyScheduleChanged() {
+	select {
+	case g.scheduleChanged <- struct{}{}:
+	default:
+	}
+}
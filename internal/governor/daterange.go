@@ -0,0 +1,140 @@
+package governor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateRange is an inclusive time window resolved from a period argument.
+type DateRange struct {
+	Start, End time.Time
+
+	// IncludePast is set for ranges that look back (last:3d, yesterday,
+	// last-week, explicit dates, this-semester): deadlines already passed
+	// in them are still listed.
+	IncludePast bool
+}
+
+// ParseDateRange resolves a period relative to now:
+//
+//	day|today, tomorrow, yesterday
+//	week|this-week, next-week, last-week      (weeks start on l.WeekStart)
+//	month|this-month, next-month, last-month
+//	year|this-year, next-year, last-year      (calendar years)
+//	this-semester                             (needs a semester start and end)
+//	next:<n><h|d|w>, last:<n><h|d|w>          from/until now, e.g. next:14d
+//	<YYYY.MM.DD>..<YYYY.MM.DD>                both days included
+//
+// Keywords are case-insensitive; "next 14d" and "next-14d" work too.
+func ParseDateRange(s string, now time.Time, l Locale, sem Semester) (DateRange, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	now = now.In(l.location())
+	today := startOfDay(now)
+	days := func(start time.Time, n int) DateRange {
+		return DateRange{Start: start, End: start.AddDate(0, 0, n).Add(-time.Nanosecond)}
+	}
+	months := func(start time.Time, n int) DateRange {
+		return DateRange{Start: start, End: start.AddDate(0, n, 0).Add(-time.Nanosecond)}
+	}
+	weekStart := startOfWeek(now, l.WeekStart)
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	january := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	past := func(r DateRange) DateRange { r.IncludePast = true; return r }
+
+	switch s {
+	case "day", "today":
+		return days(today, 1), nil
+	case "tomorrow":
+		return days(today.AddDate(0, 0, 1), 1), nil
+	case "yesterday":
+		return past(days(today.AddDate(0, 0, -1), 1)), nil
+	case "week", "this-week":
+		return days(weekStart, 7), nil
+	case "next-week":
+		return days(weekStart.AddDate(0, 0, 7), 7), nil
+	case "last-week":
+		return past(days(weekStart.AddDate(0, 0, -7), 7)), nil
+	case "month", "this-month":
+		return months(firstOfMonth, 1), nil
+	case "next-month":
+		return months(firstOfMonth.AddDate(0, 1, 0), 1), nil
+	case "last-month":
+		return past(months(firstOfMonth.AddDate(0, -1, 0), 1)), nil
+	case "year", "this-year":
+		return months(january, 12), nil
+	case "next-year":
+		return months(january.AddDate(1, 0, 0), 12), nil
+	case "last-year":
+		return past(months(january.AddDate(-1, 0, 0), 12)), nil
+	case "this-semester", "semester":
+		if sem.Start.IsZero() || sem.End.IsZero() {
+			return DateRange{}, fmt.Errorf("semester start and end are not configured")
+		}
+		start := startOfDay(sem.Start.In(now.Location()))
+		return DateRange{Start: start, End: startOfDay(sem.End.In(now.Location())).AddDate(0, 0, 1).Add(-time.Nanosecond), IncludePast: true}, nil
+	}
+
+	if from, to, ok := strings.Cut(s, ".."); ok {
+		start, err := ParseDate(from, now.Location())
+		if err != nil {
+			return DateRange{}, fmt.Errorf("range start: %w", err)
+		}
+		end, err := ParseDate(to, now.Location())
+		if err != nil {
+			return DateRange{}, fmt.Errorf("range end: %w", err)
+		}
+		if end.Before(start) {
+			return DateRange{}, fmt.Errorf("range end %s is before start %s", to, from)
+		}
+		return DateRange{Start: start, End: end.AddDate(0, 0, 1).Add(-time.Nanosecond), IncludePast: true}, nil
+	}
+
+	for _, dir := range []string{"next", "last"} {
+		rest, ok := strings.CutPrefix(s, dir)
+		if !ok || rest == "" || !strings.ContainsRune(":- ", rune(rest[0])) {
+			continue
+		}
+		d, err := parseSpan(rest[1:])
+		if err != nil {
+			return DateRange{}, err
+		}
+		if dir == "next" {
+			return DateRange{Start: now, End: now.Add(d)}, nil
+		}
+		return DateRange{Start: now.Add(-d), End: now, IncludePast: true}, nil
+	}
+	return DateRange{}, fmt.Errorf("unknown period %q", s)
+}
+
+// parseSpan parses a relative length like 36h, 14d or 2w.
+func parseSpan(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return 0, fmt.Errorf("span %q: want <n>h, <n>d or <n>w", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("span %q: want a positive count", s)
+	}
+	unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	if unit == 0 {
+		return 0, fmt.Errorf("span %q: unit must be h, d or w", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parsePeriod resolves the period args of a listing command. A relative
+// window arrives split by the wire's ":" separator (GET:DEADLINES:next:14d)
+// and is joined back before parsing.
+func (g *Governor) parsePeriod(args []string) (DateRange, error) {
+	period := args[0]
+	if len(args) >= 2 {
+		switch strings.ToLower(strings.TrimSpace(args[0])) {
+		case "next", "last":
+			period += ":" + args[1]
+		}
+	}
+	return ParseDateRange(period, g.now(), g.locale, g.semester)
+}
@@ -0,0 +1,105 @@
+package governor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	// Wednesday afternoon, mid-March.
+	now := time.Date(2026, time.March, 11, 14, 30, 0, 0, time.Local)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+	endOf := func(y int, m time.Month, d int) time.Time { return day(y, m, d+1).Add(-time.Nanosecond) }
+	sem := Semester{Start: day(2026, time.February, 9), End: day(2026, time.June, 30)}
+
+	tests := []struct {
+		in         string
+		sem        Semester
+		start, end time.Time
+		past       bool
+	}{
+		{in: "today", start: day(2026, 3, 11), end: endOf(2026, 3, 11)},
+		{in: "DAY", start: day(2026, 3, 11), end: endOf(2026, 3, 11)},
+		{in: "tomorrow", start: day(2026, 3, 12), end: endOf(2026, 3, 12)},
+		{in: "yesterday", start: day(2026, 3, 10), end: endOf(2026, 3, 10), past: true},
+		{in: "week", start: day(2026, 3, 9), end: endOf(2026, 3, 15)},
+		{in: "this-week", start: day(2026, 3, 9), end: endOf(2026, 3, 15)},
+		{in: "next-week", start: day(2026, 3, 16), end: endOf(2026, 3, 22)},
+		{in: "last-week", start: day(2026, 3, 2), end: endOf(2026, 3, 8), past: true},
+		{in: "month", start: day(2026, 3, 1), end: endOf(2026, 3, 31)},
+		{in: "next-month", start: day(2026, 4, 1), end: endOf(2026, 4, 30)},
+		{in: "last-month", start: day(2026, 2, 1), end: endOf(2026, 2, 28), past: true},
+		{in: "year", start: day(2026, 1, 1), end: endOf(2026, 12, 31)},
+		{in: "next-year", start: day(2027, 1, 1), end: endOf(2027, 12, 31)},
+		{in: "last-year", start: day(2025, 1, 1), end: endOf(2025, 12, 31), past: true},
+		{in: "next-14d", start: now, end: now.Add(14 * 24 * time.Hour)},
+		{in: "next 14d", start: now, end: now.Add(14 * 24 * time.Hour)},
+		{in: "next:36h", start: now, end: now.Add(36 * time.Hour)},
+		{in: "last:3w", start: now.Add(-21 * 24 * time.Hour), end: now, past: true},
+		{in: "2026.03.01..2026.03.15", start: day(2026, 3, 1), end: endOf(2026, 3, 15), past: true},
+		{in: "2026.03.05..2026.03.05", start: day(2026, 3, 5), end: endOf(2026, 3, 5), past: true},
+		{in: "this-semester", sem: sem, start: day(2026, 2, 9), end: endOf(2026, 6, 30), past: true},
+	}
+	for _, tt := range tests {
+		r, err := ParseDateRange(tt.in, now, DefaultLocale, tt.sem)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if !r.Start.Equal(tt.start) || !r.End.Equal(tt.end) {
+			t.Errorf("%q = [%v, %v], want [%v, %v]", tt.in, r.Start, r.End, tt.start, tt.end)
+		}
+		if r.IncludePast != tt.past {
+			t.Errorf("%q: IncludePast = %v, want %v", tt.in, r.IncludePast, tt.past)
+		}
+	}
+}
+
+func TestParseDateRangeErrors(t *testing.T) {
+	now := time.Date(2026, time.March, 11, 14, 30, 0, 0, time.Local)
+	for _, in := range []string{
+		"2026.03.15..2026.03.01", // end before start
+		"2026.03.01..",
+		"next:14x", // bad unit
+		"next:0d",
+		"last:d",
+		"this-semester", // no semester bounds
+		"fortnight",
+		"",
+	} {
+		if r, err := ParseDateRange(in, now, DefaultLocale, Semester{}); err == nil {
+			t.Errorf("%q: want error, got [%v, %v]", in, r.Start, r.End)
+		}
+	}
+}
+
+func TestParseDateRangeZone(t *testing.T) {
+	// 22:30 UTC on Wednesday is already Thursday in UTC+3.
+	now := time.Date(2026, time.March, 11, 22, 30, 0, 0, time.UTC)
+	msk := time.FixedZone("MSK", 3*60*60)
+	r, err := ParseDateRange("today", now, Locale{WeekStart: time.Monday, Lang: LangEN, Zone: msk}, Semester{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, time.March, 12, 0, 0, 0, 0, msk); !r.Start.Equal(want) {
+		t.Errorf("today in MSK begins %v, want %v", r.Start, want)
+	}
+	r, err = ParseDateRange("2026.03.01..2026.03.01", now, Locale{Zone: msk}, Semester{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, time.March, 1, 0, 0, 0, 0, msk); !r.Start.Equal(want) {
+		t.Errorf("explicit range begins %v, want %v", r.Start, want)
+	}
+}
+
+func TestParseDateRangeWeekStart(t *testing.T) {
+	now := time.Date(2026, time.March, 11, 14, 30, 0, 0, time.Local)
+	r, err := ParseDateRange("week", now, Locale{WeekStart: time.Sunday, Lang: LangEN}, Semester{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, time.March, 8, 0, 0, 0, 0, time.Local); !r.Start.Equal(want) {
+		t.Errorf("week starting Sunday begins %v, want %v", r.Start, want)
+	}
+}
@@ -2,36 +2,88 @@ package governor
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
+
+	"governor/pkg/proto"
 )
 
 // DefaultDeadlineVisibleDays is how many days before At an event starts appearing in GET:DEADLINES when VisibleFrom is not set.
 const DefaultDeadlineVisibleDays = 7
 
 type Event struct {
-	ID          string     `json:"ID"`
-	Title       string     `json:"Title"`
-	At          time.Time  `json:"At"`
-	Location    string     `json:"Location"`
-	Notes       string     `json:"Notes"`
-	VisibleFrom *time.Time `json:"VisibleFrom,omitempty"` // optional: date from which this event appears in GET:DEADLINES; nil = At - DefaultDeadlineVisibleDays
+	ID          string      `json:"ID"`
+	CreatedAt   time.Time   `json:"CreatedAt,omitzero"` // when NEW:EVENT stored it; zero for older events
+	Title       string      `json:"Title"`
+	At          time.Time   `json:"At"`
+	Location    string      `json:"Location"`
+	Notes       string      `json:"Notes"`
+	VisibleFrom *time.Time  `json:"VisibleFrom,omitempty"` // optional: date from which this event appears in GET:DEADLINES; nil = At - DefaultDeadlineVisibleDays
+	Recur       *Recurrence `json:"Recur,omitempty"`       // optional: repeat rule; At is the first occurrence
+	Tags        []string    `json:"Tags,omitempty"`        // e.g. exam, Math; matched case-insensitively
+	Priority    string      `json:"Priority,omitempty"`    // low, normal (empty) or high
+
+	Remind     []time.Duration `json:"Remind,omitempty"`     // reminder offsets before At; empty = global default
+	RemindOff  bool            `json:"RemindOff,omitempty"`  // no reminders for this event
+	RemindedAt *time.Time      `json:"RemindedAt,omitempty"` // fire time of the latest reminder already sent
+
+	Status      string        `json:"Status,omitempty"`      // open (empty), done or cancelled; occurrences can override it
+	CompletedAt *time.Time    `json:"CompletedAt,omitempty"` // when it was marked done or cancelled
+	Grace       time.Duration `json:"Grace,omitempty"`       // how long after At it still isn't overdue
+
+	DeletedAt *time.Time `json:"DeletedAt,omitempty"` // set while the event is in the trash
 }
 
 // eventWireFmt is colon-safe datetime for wire (no ":")
 const eventWireFmt = "2006.01.02.15.04"
 
-// Format: id|title|at|location|notes|visible_from (at e.g. 2025.02.21.14.30; visible_from YYYY.MM.DD or empty for default)
+// Format: id|title|at|location|notes|visible_from|recur|status|completed_at|tags|priority
+// (at e.g. 2025.02.21.14.30; visible_from YYYY.MM.DD or empty for default; recur is the rule or empty;
+// status open, done or cancelled; completed_at like at, empty while open; tags ;-separated;
+// priority low, normal or high)
 func (e Event) WireString() string {
 	at := e.At.Format(eventWireFmt)
 	visibleFrom := ""
 	if e.VisibleFrom != nil {
 		visibleFrom = e.VisibleFrom.Format("2006.01.02")
 	}
-	return strings.Join([]string{
-		noColon(e.ID), noColon(e.Title), at,
-		noColon(e.Location), noColon(e.Notes), visibleFrom,
-	}, slotSep)
+	completedAt := ""
+	if e.CompletedAt != nil {
+		completedAt = e.CompletedAt.Format(eventWireFmt)
+	}
+	return proto.JoinFields(e.ID, e.Title, at, e.Location, e.Notes, visibleFrom, e.Recur.String(),
+		e.State(), completedAt, strings.Join(e.Tags, tagSep), e.Level())
+}
+
+// clone returns a deep copy, so the copy's pointer fields can be edited
+// without touching the original.
+func (e Event) clone() Event {
+	if e.VisibleFrom != nil {
+		vf := *e.VisibleFrom
+		e.VisibleFrom = &vf
+	}
+	e.Remind = slices.Clone(e.Remind)
+	e.Tags = slices.Clone(e.Tags)
+	if e.RemindedAt != nil {
+		ra := *e.RemindedAt
+		e.RemindedAt = &ra
+	}
+	if e.CompletedAt != nil {
+		ca := *e.CompletedAt
+		e.CompletedAt = &ca
+	}
+	if e.DeletedAt != nil {
+		da := *e.DeletedAt
+		e.DeletedAt = &da
+	}
+	if e.Recur != nil {
+		r := *e.Recur
+		r.ByWeekday = slices.Clone(r.ByWeekday)
+		r.Exceptions = slices.Clone(r.Exceptions)
+		e.Recur = &r
+	}
+	return e
 }
 
 // DeadlineVisibleStart returns the time from which this event appears in GET:DEADLINES.
@@ -42,8 +94,8 @@ func (e Event) DeadlineVisibleStart() time.Time {
 	return e.At.AddDate(0, 0, -DefaultDeadlineVisibleDays)
 }
 
-// ParseEventAt parses date (YYYY.MM.DD) and time (HH.MM or HH.MM.SS) in local time
-func ParseEventAt(dateStr, timeStr string) (time.Time, error) {
+// ParseEventAt parses date (YYYY.MM.DD) and time (HH.MM or HH.MM.SS) in loc
+func ParseEventAt(dateStr, timeStr string, loc *time.Location) (time.Time, error) {
 	var y, mo, d, h, min, sec int
 	_, err := fmt.Sscanf(strings.TrimSpace(dateStr), "%d.%d.%d", &y, &mo, &d)
 	if err != nil {
@@ -70,7 +122,7 @@ func ParseEventAt(dateStr, timeStr string) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("second must be 0–59, got %d", sec)
 	}
 
-	t := time.Date(y, time.Month(mo), d, h, min, sec, 0, time.Local)
+	t := time.Date(y, time.Month(mo), d, h, min, sec, 0, loc)
 
 	// time.Date normalizes (e.g. Feb 30 -> Mar 2); check we didn't roll over.
 	if t.Day() != d || t.Month() != time.Month(mo) || t.Year() != y {
@@ -81,25 +133,118 @@ func ParseEventAt(dateStr, timeStr string) (time.Time, error) {
 
 // ParseVisibleFromDate parses an optional "visible from" date (YYYY.MM.DD) for deadline visibility.
 // Returns nil if s is empty or invalid (caller can use default).
-func ParseVisibleFromDate(s string) (*time.Time, error) {
+func ParseVisibleFromDate(s string, loc *time.Location) (*time.Time, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil, nil
 	}
+	t, err := ParseDate(s, loc)
+	if err != nil {
+		return nil, fmt.Errorf("visible_from %w", err)
+	}
+	return &t, nil
+}
+
+// ParseDate parses YYYY.MM.DD as midnight in loc.
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
 	var y, mo, d int
-	_, err := fmt.Sscanf(s, "%d.%d.%d", &y, &mo, &d)
+	_, err := fmt.Sscanf(strings.TrimSpace(s), "%d.%d.%d", &y, &mo, &d)
 	if err != nil {
-		return nil, fmt.Errorf("visible_from date: %w", err)
+		return time.Time{}, fmt.Errorf("date: %w", err)
 	}
 	if mo < 1 || mo > 12 {
-		return nil, fmt.Errorf("month must be 1–12, got %d", mo)
+		return time.Time{}, fmt.Errorf("month must be 1–12, got %d", mo)
 	}
 	if d < 1 || d > 31 {
-		return nil, fmt.Errorf("day must be 1–31, got %d", d)
+		return time.Time{}, fmt.Errorf("day must be 1–31, got %d", d)
 	}
-	t := time.Date(y, time.Month(mo), d, 0, 0, 0, 0, time.Local)
+	t := time.Date(y, time.Month(mo), d, 0, 0, 0, 0, loc)
 	if t.Day() != d || t.Month() != time.Month(mo) || t.Year() != y {
-		return nil, fmt.Errorf("invalid date: %04d.%02d.%02d", y, mo, d)
+		return time.Time{}, fmt.Errorf("invalid date: %04d.%02d.%02d", y, mo, d)
 	}
-	return &t, nil
+	return t, nil
+}
+
+// applyEventEdit sets one field of e from an EDIT field=value pair.
+// date and time may be changed independently; the other half is kept.
+// Changing when the event happens or its reminders resets the reminder watermark.
+// Dates and times are read in loc.
+func applyEventEdit(e *Event, field, value string, loc *time.Location) error {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(strings.TrimSpace(field)) {
+	case "date", "time", "recur", "remind":
+		e.RemindedAt = nil
+	}
+	switch strings.ToLower(strings.TrimSpace(field)) {
+	case "title":
+		if value == "" {
+			return replyErr{"TITLE"}
+		}
+		e.Title = value
+	case "date", "time":
+		dateStr, timeStr := e.At.In(loc).Format("2006.01.02"), e.At.In(loc).Format("15.04.05")
+		if strings.EqualFold(strings.TrimSpace(field), "date") {
+			dateStr = value
+		} else {
+			timeStr = value
+		}
+		at, err := ParseEventAt(dateStr, timeStr, loc)
+		if err != nil {
+			return replyErr{"TIME", dateStr, timeStr}
+		}
+		e.At = at
+	case "location":
+		e.Location = value
+	case "notes":
+		e.Notes = value
+	case "visible_from":
+		vf, err := ParseVisibleFromDate(value, loc)
+		if err != nil {
+			return replyErr{"VISIBLE", value}
+		}
+		e.VisibleFrom = vf
+	case "recur":
+		r, err := ParseRecurrence(value, loc)
+		if err != nil {
+			return replyErr{"RECUR", value}
+		}
+		if r != nil && e.Recur != nil {
+			r.Exceptions = e.Recur.Exceptions
+		}
+		e.Recur = r
+	case "tags":
+		// tags=exam;Math | tags= (none)
+		e.Tags = parseTags(value)
+	case "priority":
+		p, ok := parsePriority(value)
+		if !ok {
+			return replyErr{"PRIORITY", value}
+		}
+		e.Priority = p
+	case "grace":
+		// grace=2h | grace= (none)
+		var d time.Duration
+		if value != "" {
+			var err error
+			if d, err = time.ParseDuration(value); err != nil || d < 0 {
+				return replyErr{"GRACE", value}
+			}
+		}
+		e.Grace = d
+	case "remind":
+		// remind=24h,1h | remind=off | remind= (global default)
+		e.Remind, e.RemindOff = nil, false
+		if strings.EqualFold(value, "off") {
+			e.RemindOff = true
+			break
+		}
+		offsets, err := ParseRemindOffsets(value)
+		if err != nil {
+			return replyErr{"REMIND", value}
+		}
+		e.Remind = offsets
+	default:
+		return replyErr{"FIELD", field}
+	}
+	return nil
 }
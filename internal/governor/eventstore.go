@@ -4,17 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
 )
 
+// eventStore keeps events in memory, backed by a snapshot file (path) and
+// a journal (path.journal). Each mutation is applied and journaled under the
+// write lock, so mutations reach disk in the order they happened no matter
+// how many handlers run at once; the journal is folded into the snapshot
+// every journalCompactEvery records, on load and on Close.
 type eventStore struct {
-	mu     sync.RWMutex
-	byID   map[string]*Event
-	nextID int
-	path   string
+	mu      sync.RWMutex
+	byID    map[string]*Event
+	nextID  int
+	path    string
+	journal *journal // nil when path is empty
 }
 
 func newEventStore(path string) (*eventStore, error) {
@@ -30,16 +35,13 @@ func newEventStore(path string) (*eventStore, error) {
 func (s *eventStore) Load() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	data, err := os.ReadFile(s.path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("read events file %s: %w", s.path, err)
-	}
 	var list []Event
-	if err := json.Unmarshal(data, &list); err != nil {
-		return fmt.Errorf("parse events file %s: %w", s.path, err)
+	err := readRecover(s.path, func(data []byte) error {
+		list = nil
+		return json.Unmarshal(data, &list)
+	})
+	if err != nil {
+		return fmt.Errorf("load events file %s: %w", s.path, err)
 	}
 	for i := range list {
 		e := &list[i]
@@ -47,14 +49,48 @@ func (s *eventStore) Load() error {
 			slog.Warn("events load: skipping entry with empty ID", "path", s.path, "title", e.Title)
 			continue
 		}
-		s.byID[e.ID] = e
-		if n := parseEventID(e.ID); n >= s.nextID {
-			s.nextID = n + 1
+		s.put(e)
+	}
+
+	if s.journal == nil {
+		j, err := openJournal(s.path + ".journal")
+		if err != nil {
+			return fmt.Errorf("open events journal: %w", err)
+		}
+		s.journal = j
+	}
+	err = s.journal.replay(func(rec journalRecord) {
+		switch rec.Op {
+		case journalPut:
+			if rec.Event != nil {
+				e := *rec.Event
+				e.ID = rec.ID
+				s.put(&e)
+			}
+		case journalDel:
+			delete(s.byID, rec.ID)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("replay events journal: %w", err)
+	}
+	if s.journal.n > 0 {
+		slog.Info("events journal replayed", "path", s.journal.path, "records", s.journal.n)
+		if err := s.compactLocked(); err != nil {
+			slog.Error("events compaction failed after replay", "path", s.path, "err", err)
 		}
 	}
 	return nil
 }
 
+// put stores e and keeps nextID past its number. Caller holds s.mu.
+func (s *eventStore) put(e *Event) {
+	s.byID[e.ID] = e
+	if n := parseEventID(e.ID); n >= s.nextID {
+		s.nextID = n + 1
+	}
+}
+
 func parseEventID(id string) int {
 	const prefix = "ev"
 	if strings.HasPrefix(id, prefix) {
@@ -64,39 +100,82 @@ func parseEventID(id string) int {
 	return 0
 }
 
+// Save writes a snapshot of all events and empties the journal.
 func (s *eventStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compactLocked()
+}
+
+// compactLocked writes the snapshot and truncates the journal, whose records
+// it now contains. Caller holds s.mu for writing.
+func (s *eventStore) compactLocked() error {
 	if s.path == "" {
 		return nil
 	}
-	s.mu.RLock()
 	list := make([]Event, 0, len(s.byID))
 	for _, e := range s.byID {
 		list = append(list, *e)
 	}
-	s.mu.RUnlock()
 	data, err := json.MarshalIndent(list, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal events: %w", err)
 	}
-	if err := os.WriteFile(s.path, data, 0644); err != nil {
+	if err := writeSnapshot(s.path, data, 0644); err != nil {
 		return fmt.Errorf("write events file %s: %w", s.path, err)
 	}
+	if s.journal != nil {
+		if err := s.journal.reset(); err != nil {
+			return fmt.Errorf("reset events journal: %w", err)
+		}
+	}
 	return nil
 }
 
+// logLocked journals rec and compacts when the journal has grown long
+// enough. Caller holds s.mu for writing and has already applied rec.
+func (s *eventStore) logLocked(rec journalRecord) error {
+	if s.journal == nil {
+		return nil
+	}
+	if err := s.journal.append(rec); err != nil {
+		return fmt.Errorf("append events journal: %w", err)
+	}
+	if s.journal.n >= journalCompactEvery {
+		if err := s.compactLocked(); err != nil {
+			// The journal still holds everything; try again next time.
+			slog.Error("events compaction failed", "path", s.path, "err", err)
+		}
+	}
+	return nil
+}
+
+// Close compacts the journal into the snapshot and closes it.
+func (s *eventStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.journal == nil {
+		return nil
+	}
+	err := s.compactLocked()
+	if cerr := s.journal.close(); err == nil {
+		err = cerr
+	}
+	s.journal = nil
+	return err
+}
+
 func (s *eventStore) Add(e Event) (string, error) {
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.nextID++
 	id := fmt.Sprintf("ev%d", s.nextID)
 	e.ID = id
 	cp := e
 	s.byID[id] = &cp
-	s.mu.Unlock()
-	if err := s.Save(); err != nil {
+	if err := s.logLocked(journalRecord{Op: journalPut, ID: id, Event: &cp}); err != nil {
 		slog.Error("events save failed after add", "path", s.path, "id", id, "err", err)
-		s.mu.Lock()
 		delete(s.byID, id)
-		s.mu.Unlock()
 		return "", err
 	}
 	return id, nil
@@ -112,6 +191,18 @@ func (s *eventStore) List() []Event {
 	return out
 }
 
+func (s *eventStore) Query(match func(Event) bool) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Event
+	for _, e := range s.byID {
+		if match(*e) {
+			out = append(out, *e)
+		}
+	}
+	return out
+}
+
 func (s *eventStore) Get(id string) (Event, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -122,16 +213,44 @@ func (s *eventStore) Get(id string) (Event, bool) {
 	return *e, true
 }
 
+// Update applies fn to a copy of the event and stores the result atomically.
+// If fn fails or the save fails, the stored event is left unchanged.
+func (s *eventStore) Update(id string, fn func(*Event) error) (Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.byID[id]
+	if !ok {
+		return Event{}, ErrNoEvent
+	}
+	cp := old.clone()
+	if err := fn(&cp); err != nil {
+		return Event{}, err
+	}
+	cp.ID = id
+	s.byID[id] = &cp
+	if err := s.logLocked(journalRecord{Op: journalPut, ID: id, Event: &cp}); err != nil {
+		slog.Error("events save failed after update", "path", s.path, "id", id, "err", err)
+		s.byID[id] = old
+		return Event{}, err
+	}
+	return cp, nil
+}
+
 func (s *eventStore) Delete(id string) bool {
+	_, ok := s.DeleteIf(id, func(Event) bool { return true })
+	return ok
+}
+
+func (s *eventStore) DeleteIf(id string, match func(Event) bool) (Event, bool) {
 	s.mu.Lock()
-	if _, ok := s.byID[id]; !ok {
-		s.mu.Unlock()
-		return false
+	defer s.mu.Unlock()
+	e, ok := s.byID[id]
+	if !ok || !match(*e) {
+		return Event{}, false
 	}
 	delete(s.byID, id)
-	s.mu.Unlock()
-	if err := s.Save(); err != nil {
+	if err := s.logLocked(journalRecord{Op: journalDel, ID: id}); err != nil {
 		slog.Error("events save failed after delete", "path", s.path, "id", id, "err", err)
 	}
-	return true
+	return *e, true
 }
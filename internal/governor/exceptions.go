@@ -0,0 +1,315 @@
+package governor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// Schedule exception kinds.
+const (
+	ExceptHoliday  = "HOLIDAY"  // no classes that day
+	ExceptCancel   = "CANCEL"   // one class cancelled
+	ExceptRelocate = "RELOCATE" // one class in another room
+	ExceptMove     = "MOVE"     // one class rescheduled to another date/time
+)
+
+// ScheduleException overrides the weekly schedule on one date. Classes are
+// identified by their start time on that date.
+type ScheduleException struct {
+	ID       string     `json:"ID"`
+	Date     time.Time  `json:"Date"`
+	Kind     string     `json:"Kind"`
+	Start    string     `json:"Start,omitempty"`    // class start (HH.MM); empty for HOLIDAY
+	Location string     `json:"Location,omitempty"` // RELOCATE: new room
+	NewDate  *time.Time `json:"NewDate,omitempty"`  // MOVE: target date
+	NewStart string     `json:"NewStart,omitempty"` // MOVE: target start (HH.MM)
+	NewEnd   string     `json:"NewEnd,omitempty"`   // MOVE: target end (HH.MM); empty = same length
+	Note     string     `json:"Note,omitempty"`
+}
+
+// Format: id|date|kind|start|location|new_date|new_start|new_end|note
+func (x ScheduleException) WireString() string {
+	newDate := ""
+	if x.NewDate != nil {
+		newDate = x.NewDate.Format("2006.01.02")
+	}
+	return proto.JoinFields(x.ID, x.Date.Format("2006.01.02"), x.Kind, x.Start,
+		x.Location, newDate, x.NewStart, x.NewEnd, x.Note)
+}
+
+// movedSpan returns when class s starts and ends after a MOVE: at
+// NewStart, ending at NewEnd or after the class's usual length. ok is
+// false if the class would not end after it starts on the same day.
+func (x ScheduleException) movedSpan(s Slot) (start, end time.Duration, ok bool) {
+	start, err := parseClock(x.NewStart)
+	if err != nil {
+		return 0, 0, false
+	}
+	end = start + (s.EndAt - s.StartAt)
+	if x.NewEnd != "" {
+		if end, err = parseClock(x.NewEnd); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, end, end > start && end < 24*time.Hour
+}
+
+// matches reports whether x targets slot s on x.Date.
+func (x ScheduleException) matches(s Slot) bool {
+	start, err := parseClock(x.Start)
+	return err == nil && start == s.StartAt
+}
+
+// ParseScheduleException builds an exception from NEW:EXCEPTION args:
+//
+//	<date>:HOLIDAY[:<note>]
+//	<date>:CANCEL:<start>[:<note>]
+//	<date>:RELOCATE:<start>:<location>[:<note>]
+//	<date>:MOVE:<start>:<new_date>:<new_start>[:<new_end>][:<note>]
+//
+// Dates are read in loc.
+func ParseScheduleException(args []string, loc *time.Location) (ScheduleException, error) {
+	if len(args) < 2 {
+		return ScheduleException{}, replyErr{"ARGC"}
+	}
+	date, err := ParseDate(args[0], loc)
+	if err != nil {
+		return ScheduleException{}, replyErr{"DATE", args[0]}
+	}
+	x := ScheduleException{Date: date, Kind: strings.ToUpper(strings.TrimSpace(args[1]))}
+	rest := args[2:]
+	arg := func(i int) string {
+		if i < len(rest) {
+			return strings.TrimSpace(rest[i])
+		}
+		return ""
+	}
+	clock := func(s string) error {
+		if _, err := parseClock(s); err != nil {
+			return replyErr{"TIME", s}
+		}
+		return nil
+	}
+	need := map[string]int{ExceptHoliday: 0, ExceptCancel: 1, ExceptRelocate: 2, ExceptMove: 3}
+	n, ok := need[x.Kind]
+	if !ok {
+		return ScheduleException{}, replyErr{"KIND", args[1]}
+	}
+	if len(rest) < n {
+		return ScheduleException{}, replyErr{"ARGC"}
+	}
+	switch x.Kind {
+	case ExceptHoliday:
+		x.Note = arg(0)
+	case ExceptCancel:
+		x.Start, x.Note = wireClock(arg(0)), arg(1)
+	case ExceptRelocate:
+		x.Start, x.Location, x.Note = wireClock(arg(0)), arg(1), arg(2)
+	case ExceptMove:
+		nd, err := ParseDate(arg(1), loc)
+		if err != nil {
+			return ScheduleException{}, replyErr{"DATE", arg(1)}
+		}
+		x.Start, x.NewDate, x.NewStart = wireClock(arg(0)), &nd, wireClock(arg(2))
+		x.NewEnd, x.Note = wireClock(arg(3)), arg(4)
+		for _, c := range []string{x.NewStart, x.NewEnd} {
+			if c == "" {
+				continue
+			}
+			if err := clock(c); err != nil {
+				return ScheduleException{}, err
+			}
+		}
+		if x.NewEnd != "" {
+			// Same rule as CSV rows: the end must be after the start.
+			start, _ := parseClock(x.NewStart)
+			if end, _ := parseClock(x.NewEnd); end <= start {
+				return ScheduleException{}, replyErr{"TIME", x.NewEnd}
+			}
+		}
+	}
+	if x.Start != "" {
+		if err := clock(x.Start); err != nil {
+			return ScheduleException{}, err
+		}
+	}
+	return x, nil
+}
+
+type exceptionStore struct {
+	mu     sync.RWMutex
+	byID   map[string]*ScheduleException
+	nextID int
+	path   string
+}
+
+func newExceptionStore(path string) (*exceptionStore, error) {
+	s := &exceptionStore{byID: make(map[string]*ScheduleException), path: path}
+	if path != "" {
+		if err := s.Load(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *exceptionStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []ScheduleException
+	err := readRecover(s.path, func(data []byte) error {
+		list = nil
+		return json.Unmarshal(data, &list)
+	})
+	if err != nil {
+		return fmt.Errorf("load exceptions file %s: %w", s.path, err)
+	}
+	for i := range list {
+		x := &list[i]
+		if x.ID == "" {
+			slog.Warn("exceptions load: skipping entry with empty ID", "path", s.path, "date", x.Date)
+			continue
+		}
+		s.byID[x.ID] = x
+		if n, _ := strconv.Atoi(strings.TrimPrefix(x.ID, "ex")); n >= s.nextID {
+			s.nextID = n
+		}
+	}
+	return nil
+}
+
+// Save writes all exceptions to the file.
+func (s *exceptionStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+// saveLocked writes the file under the write lock, so concurrent changes
+// reach disk in the order they were made and snapshot rotations never
+// overlap.
+func (s *exceptionStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.listLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal exceptions: %w", err)
+	}
+	if err := writeSnapshot(s.path, data, 0644); err != nil {
+		return fmt.Errorf("write exceptions file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *exceptionStore) Add(x ScheduleException) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := fmt.Sprintf("ex%d", s.nextID)
+	x.ID = id
+	s.byID[id] = &x
+	if err := s.saveLocked(); err != nil {
+		slog.Error("exceptions save failed after add", "path", s.path, "id", id, "err", err)
+		delete(s.byID, id)
+		return "", err
+	}
+	return id, nil
+}
+
+// List returns all exceptions ordered by date.
+func (s *exceptionStore) List() []ScheduleException {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listLocked()
+}
+
+func (s *exceptionStore) listLocked() []ScheduleException {
+	out := make([]ScheduleException, 0, len(s.byID))
+	for _, x := range s.byID {
+		out = append(out, *x)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.Before(out[j].Date)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func (s *exceptionStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byID[id]; !ok {
+		return false
+	}
+	delete(s.byID, id)
+	if err := s.saveLocked(); err != nil {
+		slog.Error("exceptions save failed after delete", "path", s.path, "id", id, "err", err)
+	}
+	return true
+}
+
+// applyExceptions overrides the weekly slots of day with the exceptions
+// for that date, and adds classes moved onto it from other dates.
+func (g *Governor) applyExceptions(day time.Time, slots []Slot) []Slot {
+	day = startOfDay(day)
+	var out []Slot
+	all := g.exceptions.List()
+	for _, x := range all {
+		if x.Kind == ExceptHoliday && x.Date.Equal(day) {
+			slots = nil
+		}
+	}
+	for _, s := range slots {
+		keep := true
+		for _, x := range all {
+			if !x.Date.Equal(day) || !x.matches(s) {
+				continue
+			}
+			switch x.Kind {
+			case ExceptCancel, ExceptMove:
+				keep = false
+			case ExceptRelocate:
+				s.Location = x.Location
+			}
+		}
+		if keep {
+			out = append(out, s)
+		}
+	}
+	for _, x := range all {
+		if x.Kind != ExceptMove || x.NewDate == nil || !x.NewDate.Equal(day) {
+			continue
+		}
+		for _, s := range g.weeklySlotsOn(x.Date) {
+			if !x.matches(s) {
+				continue
+			}
+			start, end, ok := x.movedSpan(s)
+			if !ok {
+				continue // rejected on NEW:EXCEPTION; only an edited file gets here
+			}
+			s.Weekday = g.locale.WeekdayName(day.Weekday())
+			s.Day = day.Weekday()
+			s.Start, s.StartAt = x.NewStart, start
+			s.End, s.EndAt = clockString(end), end
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// clockString formats an offset from midnight as HH.MM.
+func clockString(d time.Duration) string {
+	return fmt.Sprintf("%02d.%02d", int(d.Hours()), int(d.Minutes())%60)
+}
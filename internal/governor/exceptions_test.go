@@ -0,0 +1,70 @@
+package governor
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExceptionStoreConcurrentAdds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exceptions.json")
+	s, err := newExceptionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.Local)
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Add(ScheduleException{Date: date, Kind: ExceptHoliday}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	reloaded, err := newExceptionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reloaded.List()); got != 50 {
+		t.Fatalf("file has %d exceptions after 50 concurrent adds, want 50", got)
+	}
+}
+
+func TestParseMoveRejectsEndNotAfterStart(t *testing.T) {
+	for _, args := range [][]string{
+		{"2026.03.09", "MOVE", "10.45", "2026.03.10", "12.00", "12.00"},
+		{"2026.03.09", "MOVE", "10.45", "2026.03.10", "12.00", "11.30"},
+	} {
+		_, err := ParseScheduleException(args, time.Local)
+		if rerr, ok := err.(replyErr); !ok || rerr[0] != "TIME" {
+			t.Errorf("%q: err = %v, want ERR:TIME", args, err)
+		}
+	}
+	if _, err := ParseScheduleException([]string{"2026.03.09", "MOVE", "10.45", "2026.03.10", "12.00", "13.30"}, time.Local); err != nil {
+		t.Errorf("valid move rejected: %v", err)
+	}
+}
+
+func TestMovedSpan(t *testing.T) {
+	slot := Slot{StartAt: 10*time.Hour + 45*time.Minute, EndAt: 12*time.Hour + 10*time.Minute}
+	tests := []struct {
+		newStart, newEnd string
+		ok               bool
+	}{
+		{"14.00", "", true},
+		{"23.00", "", false}, // 85 minutes from 23.00 runs past midnight
+		{"22.30", "23.59", true},
+		{"23.00", "22.00", false},
+	}
+	for _, tt := range tests {
+		x := ScheduleException{Kind: ExceptMove, NewStart: tt.newStart, NewEnd: tt.newEnd}
+		if _, _, ok := x.movedSpan(slot); ok != tt.ok {
+			t.Errorf("move to %s-%s: ok = %v, want %v", tt.newStart, tt.newEnd, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package governor
+
+import (
+	"cmp"
+	"strings"
+	"time"
+)
+
+// EventFilter selects events by key=value conditions, all of which must
+// hold. The zero value matches everything.
+//
+//	from=<YYYY.MM.DD>  to=<YYYY.MM.DD>   deadline within [from, to], both inclusive
+//	status=<open|done|cancelled|overdue|all>
+//	tag=<tag>          repeatable; every tag must be present
+//	priority=<low|normal|high>
+//	location=<text>    title=<text>     case-insensitive substring (ё = е)
+type EventFilter struct {
+	From     time.Time // zero = unbounded
+	To       time.Time // zero = unbounded; exclusive end (midnight after the to= date)
+	Status   string    // "" or "all" = any
+	Tags     []string
+	Priority string // "" = any
+	Location string
+	Title    string
+}
+
+// Set applies one key=value condition. known is false if key isn't a
+// filter key, so callers can handle their own options alongside. Dates
+// are read in loc.
+func (f *EventFilter) Set(key, val string, loc *time.Location) (known bool, err error) {
+	val = strings.TrimSpace(val)
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "from":
+		d, err := ParseDate(val, loc)
+		if err != nil {
+			return true, replyErr{"DATE", val}
+		}
+		f.From = d
+	case "to":
+		d, err := ParseDate(val, loc)
+		if err != nil {
+			return true, replyErr{"DATE", val}
+		}
+		f.To = d.AddDate(0, 0, 1)
+	case "status":
+		s := strings.ToLower(val)
+		if _, ok := statusMatcher(s, time.Time{}); !ok {
+			return true, replyErr{"STATUS", val}
+		}
+		f.Status = s
+	case "tag":
+		f.Tags = append(f.Tags, val)
+	case "priority":
+		p, ok := parsePriority(val)
+		if !ok || val == "" {
+			return true, replyErr{"PRIORITY", val}
+		}
+		f.Priority = cmp.Or(p, PriorityNormal)
+	case "location":
+		f.Location = val
+	case "title":
+		f.Title = val
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Bounded reports whether the filter limits deadlines to a date range.
+func (f EventFilter) Bounded() bool { return !f.From.IsZero() || !f.To.IsZero() }
+
+// Window narrows the inclusive range [start, end] to the filter's dates.
+// Zero bounds are open; if only the start is known afterwards, the range
+// is capped a year later so recurring events stay finite.
+func (f EventFilter) Window(start, end time.Time) (time.Time, time.Time) {
+	if !f.From.IsZero() && f.From.After(start) {
+		start = f.From
+	}
+	if !f.To.IsZero() {
+		if to := f.To.Add(-time.Nanosecond); end.IsZero() || to.Before(end) {
+			end = to
+		}
+	}
+	if end.IsZero() {
+		end = start.AddDate(1, 0, 0)
+	}
+	return start, end
+}
+
+// Match reports whether e passes every condition; overdue is judged at now.
+func (f EventFilter) Match(e Event, now time.Time) bool {
+	if !f.From.IsZero() && e.At.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !e.At.Before(f.To) {
+		return false
+	}
+	if status, _ := statusMatcher(f.Status, now); !status(e) {
+		return false
+	}
+	if f.Priority != "" && e.Level() != f.Priority {
+		return false
+	}
+	for _, t := range f.Tags {
+		if !e.HasTag(t) {
+			return false
+		}
+	}
+	if f.Location != "" && !strings.Contains(normalizeText(e.Location), normalizeText(f.Location)) {
+		return false
+	}
+	if f.Title != "" && !strings.Contains(normalizeText(e.Title), normalizeText(f.Title)) {
+		return false
+	}
+	return true
+}
@@ -1,8 +1,12 @@
 package governor
 
 import (
+	"errors"
 	log "log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"governor/pkg/proto"
@@ -10,34 +14,153 @@ import (
 
 const DefaultDeadlinePeriod = 7 * 24 * time.Hour
 
+// DefaultClassLead is how long before a class starts its notice is sent.
+const DefaultClassLead = 10 * time.Minute
+
+// DefaultRemindOffsets are used for events that don't set their own.
+var DefaultRemindOffsets = []time.Duration{24 * time.Hour, time.Hour}
+
 type Governor struct {
 	client         *proto.Client
 	bootedAt       time.Time
 	schedule       []Slot
-	events         *eventStore
+	schedulePath   string
+	scheduleMu     sync.RWMutex
+	reloadMu       sync.Mutex // serializes ReloadSchedule
+	strictSchedule bool
+	semester       Semester
+	locale         Locale
+	exceptions     *exceptionStore
+	exceptionsPath string
+	events         EventRepository
+	history        *historyLog
+	historyPath    string
+	trashRetention time.Duration
 	deadlinePeriod time.Duration
+
+	remindTo      string
+	remindDefault []time.Duration
+
+	classTo         string
+	classLead       time.Duration
+	scheduleChanged chan struct{}
+
+	stop chan struct{}
+	wg   sync.WaitGroup
 }
 
-func New(client *proto.Client, schedulePath, eventsPath string) (*Governor, error) {
-	events, err := newEventStore(eventsPath)
-	if err != nil {
-		return nil, err
+type Option func(*Governor)
+
+// WithReminders pushes NEW:REMINDER messages to node before each event's
+// deadline. offsets are the global default; events may override them.
+func WithReminders(node string, offsets ...time.Duration) Option {
+	return func(g *Governor) {
+		g.remindTo = strings.ToUpper(node)
+		if len(offsets) > 0 {
+			g.remindDefault = offsets
+		}
+	}
+}
+
+// WithClassNotices pushes NEW:CLASS:START lead before each scheduled class
+// and NEW:CLASS:END when it ends, to node.
+func WithClassNotices(node string, lead time.Duration) Option {
+	return func(g *Governor) {
+		g.classTo = strings.ToUpper(node)
+		g.classLead = lead
 	}
+}
 
+// WithSemester bounds the weekly schedule to [start, end] and numbers
+// teaching weeks from start, for odd/even and week-list rows.
+func WithSemester(start, end time.Time) Option {
+	return func(g *Governor) { g.semester = Semester{Start: start, End: end} }
+}
+
+// WithStrictSchedule makes any schedule CSV problem fatal: governor
+// refuses to boot, and reloads keep the current schedule.
+func WithStrictSchedule(strict bool) Option {
+	return func(g *Governor) { g.strictSchedule = strict }
+}
+
+// WithExceptionsFile persists schedule exceptions (holidays, cancelled,
+// moved or relocated classes) to path.
+func WithExceptionsFile(path string) Option {
+	return func(g *Governor) { g.exceptionsPath = path }
+}
+
+// WithEventRepository stores events in repo instead of the JSON file given
+// to New. The governor closes repo on Shutdown.
+func WithEventRepository(repo EventRepository) Option {
+	return func(g *Governor) { g.events = repo }
+}
+
+// WithHistoryFile appends the audit trail of event changes to path.
+// Without it, a governor that opens its own JSON events file keeps the
+// trail next to it (<events>.history); otherwise it is only in memory.
+func WithHistoryFile(path string) Option {
+	return func(g *Governor) { g.historyPath = path }
+}
+
+func New(client *proto.Client, schedulePath, eventsPath string, opts ...Option) (*Governor, error) {
 	g := &Governor{
 		client:         client,
 		bootedAt:       time.Now(),
-		events:         events,
 		deadlinePeriod: DefaultDeadlinePeriod,
+		remindDefault:  DefaultRemindOffsets,
+		classLead:      DefaultClassLead,
+		trashRetention: DefaultTrashRetention,
+		locale:         DefaultLocale,
+		stop:           make(chan struct{}),
+	}
+	g.scheduleChanged = make(chan struct{}, 1)
+	for _, o := range opts {
+		o(g)
+	}
+
+	var err error
+	if g.events == nil {
+		if g.events, err = NewJSONEventRepository(eventsPath); err != nil {
+			return nil, err
+		}
+		if g.historyPath == "" && eventsPath != "" {
+			g.historyPath = eventsPath + ".history"
+		}
+	}
+	if g.history, err = newHistoryLog(g.historyPath); err != nil {
+		return nil, err
+	}
+
+	g.exceptions, err = newExceptionStore(g.exceptionsPath)
+	if err != nil {
+		return nil, err
 	}
 
 	if schedulePath != "" {
-		slots, err := LoadScheduleFromCSV(schedulePath)
+		slots, err := g.loadSchedule(schedulePath)
 		if err != nil {
 			return nil, err
 		}
 		g.schedule = slots
+		g.schedulePath = schedulePath
 		log.Debug("schedule loaded", "path", schedulePath, "slots", len(slots))
+		g.wg.Add(1)
+		go g.scheduleWatchLoop()
+	}
+
+	if g.remindTo != "" {
+		g.wg.Add(1)
+		go g.reminderLoop()
+		log.Debug("reminders enabled", "to", g.remindTo, "offsets", g.remindDefault)
+	}
+	if g.classTo != "" {
+		g.wg.Add(1)
+		go g.classLoop()
+		log.Debug("class notices enabled", "to", g.classTo, "lead", g.classLead)
+	}
+	if g.trashRetention > 0 {
+		g.wg.Add(1)
+		go g.trashLoop()
 	}
 
 	return g, nil
@@ -49,16 +172,39 @@ func (g *Governor) reply(req *proto.Request, verb, noun string, args ...string)
 	}
 }
 
+// replyErr is a validation failure that maps directly onto an ERR reply:
+// the first element is the reason (noun), the rest are its args.
+type replyErr []string
+
+func (e replyErr) Error() string { return "ERR:" + strings.Join(e, ":") }
+
+func (g *Governor) replyError(req *proto.Request, e replyErr) {
+	g.reply(req, "ERR", e[0], e[1:]...)
+}
+
 // Cmd dispatches an incoming request by verb.
 //
 //	PING        -> PONG PONG
 //	NEW  EVENT  -> OK EVENT <id>
-//	STOP EVENT  -> OK EVENT <id> | ERR NAC
+//	STOP EVENT  -> OK EVENT <id> | ERR NAC  (moves it to the trash)
+//	NEW  RESTORE <id> -> OK RESTORE <id> | ERR NAC
+//	NEW  EXCEPTION <date> <kind> ... -> OK EXCEPTION <id>
+//	NEW  RELOAD SCHEDULE -> OK RELOAD SCHEDULE <slots> | ERR RELOAD <reason>
+//	STOP EXCEPTION <id> -> OK EXCEPTION <id> | ERR NAC
+//	EDIT EVENT <id> <field>=<value>... -> OK EVENT <wire> | ERR NAC
+//	DONE|CANCEL|REOPEN EVENT <id> -> OK EVENT <wire> | ERR NAC
 //	GET  UPTIME -> OK UPTIME <dur>
-//	GET  SCHEDULE <weekday> -> OK SCHEDULE [<slot>...]
-//	GET  EVENTS     -> OK EVENTS [<event>...]
+//	GET  SCHEDULE <weekday|TODAY|TOMORROW> -> OK SCHEDULE [<slot>...]
+//	GET  SCHEDULE NOW|NEXT -> OK SCHEDULE [<slot> <minutes>]
+//	GET  SCHEDULE DATE <date> -> OK SCHEDULE [<slot>...]  (exceptions applied)
+//	GET  EXCEPTIONS -> OK EXCEPTIONS [<exception>...]
+//	GET  EVENTS [period] [<filter>...] [sort= order= offset= limit=] -> OK EVENTS [<event>...] [next=<offset>]  (period or from=/to=: occurrences in that window)
 //	GET  EVENT <id> -> OK EVENT <wire> | ERR NAC
-//	GET  DEADLINES [day|week|month] -> OK DEADLINES [<event>...]  (no arg: configured period; else calendar window)
+//	GET  SEARCH <query> -> OK SEARCH [EVENT|<event> | SLOT|<date>|<slot>...]  (best match first)
+//	GET  OVERDUE [<filter>...] -> OK OVERDUE [<event>...]  (most late first)
+//	GET  TRASH [<filter>...] -> OK TRASH [<event>...]
+//	GET  HISTORY [id] [offset= limit=] -> OK HISTORY [<entry>...] [next=<offset>]
+//	GET  DEADLINES [period] [<filter>...] [sort= order= offset= limit=] -> OK DEADLINES [<event>...] [next=<offset>]  (no arg: configured period; else that window, see ParseDateRange; open only by default)
 func (g *Governor) Cmd(req *proto.Request) {
 	msg := req.Msg
 	log.Debug("CMD", "from", msg.From, "verb", msg.Verb, "noun", msg.Noun, "args", msg.Args)
@@ -73,6 +219,10 @@ func (g *Governor) Cmd(req *proto.Request) {
 		g.cmdNew(req)
 	case "STOP":
 		g.cmdStop(req)
+	case "EDIT":
+		g.cmdEdit(req)
+	case "DONE", "CANCEL", "REOPEN":
+		g.cmdStatus(req)
 	case "GET":
 		g.cmdGet(req)
 	default:
@@ -94,23 +244,55 @@ func (g *Governor) cmdGet(req *proto.Request) {
 			g.reply(req, "ERR", "ARGC")
 			return
 		}
-		weekday := strings.TrimSpace(msg.Args[0])
-		var slots []string
-		for i := range g.schedule {
-			if strings.EqualFold(g.schedule[i].Weekday, weekday) {
-				slots = append(slots, g.schedule[i].WireString())
-			}
-		}
-		log.Debug("GET SCHEDULE", "weekday", weekday, "slots", len(slots), "from", msg.From)
-		g.reply(req, "OK", "SCHEDULE", slots...)
+		g.getSchedule(req, strings.TrimSpace(msg.Args[0]), msg.Args[1:])
 
-	case "EVENTS":
-		all := g.events.List()
+	case "EXCEPTIONS":
+		all := g.exceptions.List()
 		args := make([]string, len(all))
 		for i := range all {
 			args[i] = all[i].WireString()
 		}
-		log.Debug("GET EVENTS", "count", len(args), "from", msg.From)
+		log.Debug("GET EXCEPTIONS", "count", len(args), "from", msg.From)
+		g.reply(req, "OK", "EXCEPTIONS", args...)
+
+	case "EVENTS":
+		all := g.liveEvents()
+		q, rest, ok := g.parseListArgs(req, "all")
+		if !ok {
+			return
+		}
+		match := q.match(g.now())
+		var found []Event
+		if len(rest) >= 1 || q.filter.Bounded() {
+			// GET:EVENTS:<period> or from=/to=: concrete occurrences in that window.
+			var start, end time.Time
+			if len(rest) >= 1 {
+				r, err := g.parsePeriod(rest)
+				if err != nil {
+					log.Warn("GET EVENTS bad period", "period", strings.Join(rest, ":"), "err", err, "from", msg.From)
+					g.reply(req, "ERR", "PERIOD", strings.Join(rest, ":"))
+					return
+				}
+				start, end = r.Start, r.End
+			}
+			start, end = q.filter.Window(start, end)
+			for i := range all {
+				for _, inst := range all[i].Occurrences(start, end) {
+					if match(inst) {
+						found = append(found, inst)
+					}
+				}
+			}
+		} else {
+			// No period: stored events as-is, recurring ones as their series.
+			for i := range all {
+				if match(all[i]) {
+					found = append(found, all[i])
+				}
+			}
+		}
+		args := q.pageArgs(found)
+		log.Debug("GET EVENTS", "count", len(found), "from", msg.From)
 		g.reply(req, "OK", "EVENTS", args...)
 
 	case "EVENT":
@@ -119,7 +301,7 @@ func (g *Governor) cmdGet(req *proto.Request) {
 			return
 		}
 		id := strings.TrimSpace(msg.Args[0])
-		e, ok := g.events.Get(id)
+		e, ok := g.getEvent(id)
 		if !ok {
 			g.reply(req, "ERR", "NAC")
 			return
@@ -127,42 +309,107 @@ func (g *Governor) cmdGet(req *proto.Request) {
 		log.Debug("GET EVENT", "id", id, "from", msg.From)
 		g.reply(req, "OK", "EVENT", e.WireString())
 
+	case "SEARCH":
+		// GET:SEARCH:<query>: words of the query may span several args.
+		query := strings.Join(msg.Args, " ")
+		if strings.TrimSpace(query) == "" {
+			g.reply(req, "ERR", "ARGC")
+			return
+		}
+		results := g.search(query, g.now())
+		args := make([]string, len(results))
+		for i := range results {
+			args[i] = results[i].WireString()
+		}
+		log.Debug("GET SEARCH", "query", query, "count", len(args), "from", msg.From)
+		g.reply(req, "OK", "SEARCH", args...)
+
+	case "OVERDUE":
+		// GET:OVERDUE[:<filter>...]: open items past deadline + grace, latest first.
+		q, _, ok := g.parseListArgs(req, "all")
+		if !ok {
+			return
+		}
+		now := g.now()
+		items := slices.DeleteFunc(g.overdue(now), func(o overdueItem) bool { return !q.filter.Match(o.Event, now) })
+		args := pageItems(q, items, func(o overdueItem) Event { return o.Event }, overdueItem.WireString)
+		log.Debug("GET OVERDUE", "count", len(items), "from", msg.From)
+		g.reply(req, "OK", "OVERDUE", args...)
+
+	case "TRASH":
+		q, _, ok := g.parseListArgs(req, "all")
+		if !ok {
+			return
+		}
+		match := q.match(g.now())
+		all := slices.DeleteFunc(g.trash(), func(e Event) bool { return !match(e) })
+		args := pageItems(q, all, func(e Event) Event { return e }, Event.TrashWireString)
+		log.Debug("GET TRASH", "count", len(all), "from", msg.From)
+		g.reply(req, "OK", "TRASH", args...)
+
+	case "HISTORY":
+		// GET:HISTORY[:<id>][:offset=<n>][:limit=<n>]: audit trail, oldest
+		// first; a series ID includes its occurrences.
+		q, rest, err := parsePageQuery(msg.Args)
+		var rerr replyErr
+		if errors.As(err, &rerr) {
+			log.Warn("GET HISTORY bad args", "args", msg.Args, "from", msg.From, "err", err)
+			g.replyError(req, rerr)
+			return
+		}
+		var id string
+		if len(rest) >= 1 {
+			id = strings.TrimSpace(rest[0])
+		}
+		entries := g.history.List(id)
+		args := pageItems(q, entries, nil, HistoryEntry.WireString)
+		log.Debug("GET HISTORY", "id", id, "count", len(entries), "from", msg.From)
+		g.reply(req, "OK", "HISTORY", args...)
+
 	case "DEADLINES":
-		all := g.events.List()
-		now := time.Now()
-		var args []string
-
-		if len(msg.Args) >= 1 {
-			// Specific calendar window: GET:DEADLINES:DAY|WEEK|MONTH|YEAR
-			// Include event if At is in [start,end] and now is within event's visible window [visibleStart, At]
-			start, end := periodBounds(msg.Args[0])
-			if start.IsZero() && end.IsZero() {
-				log.Warn("GET DEADLINES unknown period", "period", msg.Args[0], "from", msg.From)
-				g.reply(req, "ERR", "PERIOD")
+		all := g.liveEvents()
+		now := g.now()
+		var found []Event
+		// Done and cancelled items are hidden unless status= asks for them.
+		q, rest, ok := g.parseListArgs(req, StatusOpen)
+		if !ok {
+			return
+		}
+		match := q.match(now)
+
+		if len(rest) >= 1 {
+			// Specific window (see ParseDateRange): GET:DEADLINES:week, :next:14d, :2026.03.01..2026.03.15
+			// Include event if At is in [start,end] and now is within event's visible window [visibleStart, At+grace];
+			// ranges reaching into the past also keep deadlines already due.
+			r, err := g.parsePeriod(rest)
+			if err != nil {
+				log.Warn("GET DEADLINES bad period", "period", strings.Join(rest, ":"), "err", err, "from", msg.From)
+				g.reply(req, "ERR", "PERIOD", strings.Join(rest, ":"))
 				return
 			}
 			for i := range all {
-				e := &all[i]
-				at := e.At
-				visibleStart := e.DeadlineVisibleStart()
-				if !at.Before(start) && !at.After(end) && !now.Before(visibleStart) && !now.After(at) {
-					args = append(args, e.WireString())
+				for _, e := range all[i].Occurrences(r.Start, r.End) {
+					if match(e) && !now.Before(e.DeadlineVisibleStart()) && (r.IncludePast || !now.After(e.Due())) {
+						found = append(found, e)
+					}
 				}
 			}
-			log.Debug("GET DEADLINES", "window", msg.Args[0], "start", start.Format("2006-01-02"), "end", end.Format("2006-01-02"), "count", len(args), "from", msg.From)
+			log.Debug("GET DEADLINES", "window", strings.Join(rest, ":"), "start", r.Start.Format("2006-01-02 15:04"), "end", r.End.Format("2006-01-02 15:04"), "count", len(found), "from", msg.From)
 		} else {
-			// No window arg: return events that are currently in their visible window (visibleStart <= now <= At).
+			// No window arg: return events that are currently in their visible window (visibleStart <= now <= At+grace).
 			// Default visibleStart = At - 7 days; can be overridden per event via VisibleFrom.
+			// Recurring events: only occurrences due within their visible span from now can qualify.
 			for i := range all {
-				e := &all[i]
-				visibleStart := e.DeadlineVisibleStart()
-				if !now.Before(visibleStart) && !now.After(e.At) {
-					args = append(args, e.WireString())
+				span := all[i].At.Sub(all[i].DeadlineVisibleStart())
+				for _, e := range all[i].Occurrences(now.Add(-all[i].Grace), now.Add(span)) {
+					if match(e) && !now.Before(e.DeadlineVisibleStart()) && !now.After(e.Due()) {
+						found = append(found, e)
+					}
 				}
 			}
-			log.Debug("GET DEADLINES", "now", now.Format("2006-01-02 15:04"), "count", len(args), "from", msg.From)
+			log.Debug("GET DEADLINES", "now", now.Format("2006-01-02 15:04"), "count", len(found), "from", msg.From)
 		}
-		g.reply(req, "OK", "DEADLINES", args...)
+		g.reply(req, "OK", "DEADLINES", q.pageArgs(found)...)
 
 	default:
 		log.Warn("UNKNOWN NOUN", "noun", msg.Noun, "from", msg.From)
@@ -186,7 +433,7 @@ func (g *Governor) cmdNew(req *proto.Request) {
 		}
 		dateStr := msg.Args[1]
 		timeStr := msg.Args[2]
-		at, err := ParseEventAt(dateStr, timeStr)
+		at, err := ParseEventAt(dateStr, timeStr, g.locale.location())
 		if err != nil {
 			log.Warn("BAD EVENT TIME", "date", dateStr, "time", timeStr, "from", msg.From, "err", err)
 			g.reply(req, "ERR", "TIME", dateStr, timeStr)
@@ -201,7 +448,7 @@ func (g *Governor) cmdNew(req *proto.Request) {
 		}
 		var visibleFrom *time.Time
 		if len(msg.Args) > 5 {
-			vf, err := ParseVisibleFromDate(msg.Args[5])
+			vf, err := ParseVisibleFromDate(msg.Args[5], g.locale.location())
 			if err != nil {
 				log.Warn("NEW EVENT bad visible_from", "visible_from", msg.Args[5], "from", msg.From, "err", err)
 				g.reply(req, "ERR", "VISIBLE", msg.Args[5])
@@ -209,15 +456,100 @@ func (g *Governor) cmdNew(req *proto.Request) {
 			}
 			visibleFrom = vf
 		}
-		e := Event{Title: title, At: at, Location: location, Notes: notes, VisibleFrom: visibleFrom}
+		e := Event{Title: title, At: at, Location: location, Notes: notes, VisibleFrom: visibleFrom, CreatedAt: g.now()}
+		// Anything after visible_from is field=value, same fields as EDIT (e.g. recur=...).
+		if len(msg.Args) > 6 {
+			for _, kv := range msg.Args[6:] {
+				field, value, ok := strings.Cut(kv, "=")
+				if !ok {
+					g.reply(req, "ERR", "FIELD", kv)
+					return
+				}
+				var rerr replyErr
+				if err := applyEventEdit(&e, field, value, g.locale.location()); errors.As(err, &rerr) {
+					log.Warn("NEW EVENT bad field", "field", kv, "from", msg.From, "err", err)
+					g.replyError(req, rerr)
+					return
+				}
+			}
+		}
 		id, err := g.events.Add(e)
 		if err != nil {
 			log.Error("NEW EVENT add failed", "title", title, "from", msg.From, "err", err)
 			g.reply(req, "ERR", "ADD", err.Error())
 			return
 		}
+		e.ID = id
+		g.record(msg.From, "NEW", id, nil, &e)
 		log.Info("NEW EVENT", "id", id, "title", title, "at", at.Format("2006-01-02 15:04"), "from", msg.From)
 		g.reply(req, "OK", "EVENT", id)
+	case "RESTORE":
+		if len(msg.Args) < 1 {
+			g.reply(req, "ERR", "ARGC")
+			return
+		}
+		id := strings.TrimSpace(msg.Args[0])
+		e, err := g.restoreEvent(id)
+		if errors.Is(err, ErrNoEvent) {
+			log.Debug("NEW RESTORE not in trash", "id", id, "from", msg.From)
+			g.reply(req, "ERR", "NAC")
+			return
+		}
+		if err != nil {
+			log.Error("NEW RESTORE failed", "id", id, "from", msg.From, "err", err)
+			g.reply(req, "ERR", "RESTORE", err.Error())
+			return
+		}
+		g.record(msg.From, "RESTORE", id, nil, &e)
+		log.Info("NEW RESTORE", "id", id, "title", e.Title, "from", msg.From)
+		g.reply(req, "OK", "RESTORE", id)
+	case "RELOAD":
+		if len(msg.Args) < 1 {
+			g.reply(req, "ERR", "ARGC")
+			return
+		}
+		if !strings.EqualFold(strings.TrimSpace(msg.Args[0]), "SCHEDULE") {
+			g.reply(req, "ERR", "RELOAD", msg.Args[0])
+			return
+		}
+		n, err := g.ReloadSchedule()
+		if err != nil {
+			g.reply(req, "ERR", "RELOAD", err.Error())
+			return
+		}
+		log.Info("NEW RELOAD SCHEDULE", "slots", n, "from", msg.From)
+		g.reply(req, "OK", "RELOAD", "SCHEDULE", strconv.Itoa(n))
+	case "EXCEPTION":
+		x, err := ParseScheduleException(msg.Args, g.locale.location())
+		var rerr replyErr
+		if errors.As(err, &rerr) {
+			log.Warn("NEW EXCEPTION rejected", "args", msg.Args, "from", msg.From, "err", err)
+			g.replyError(req, rerr)
+			return
+		}
+		if x.Kind != ExceptHoliday && !slices.ContainsFunc(g.weeklySlotsOn(x.Date), x.matches) {
+			log.Warn("NEW EXCEPTION no such class", "date", x.Date.Format("2006-01-02"), "start", x.Start, "from", msg.From)
+			g.reply(req, "ERR", "SLOT", x.Start)
+			return
+		}
+		if x.Kind == ExceptMove {
+			for _, s := range g.weeklySlotsOn(x.Date) {
+				if _, _, ok := x.movedSpan(s); x.matches(s) && !ok {
+					log.Warn("NEW EXCEPTION moved class runs past midnight", "date", x.Date.Format("2006-01-02"), "start", x.Start, "new_start", x.NewStart, "from", msg.From)
+					g.reply(req, "ERR", "TIME", x.NewStart)
+					return
+				}
+			}
+		}
+		id, err := g.exceptions.Add(x)
+		if err != nil {
+			log.Error("NEW EXCEPTION add failed", "from", msg.From, "err", err)
+			g.reply(req, "ERR", "ADD", err.Error())
+			return
+		}
+		log.Info("NEW EXCEPTION", "id", id, "kind", x.Kind, "date", x.Date.Format("2006-01-02"), "start", x.Start, "from", msg.From)
+		g.notifyScheduleChanged()
+		g.reply(req, "OK", "EXCEPTION", id)
 	default:
 		log.Warn("UNKNOWN NOUN", "noun", msg.Noun, "from", msg.From)
 		g.reply(req, "ERR", "NOUN")
@@ -233,17 +565,239 @@ func (g *Governor) cmdStop(req *proto.Request) {
 			return
 		}
 		id := strings.TrimSpace(msg.Args[0])
-		if !g.events.Delete(id) {
+		if series, day, ok := splitInstanceID(id, g.locale.location()); ok {
+			// Stopping one occurrence skips it; the series stays.
+			var before Event
+			_, err := g.updateLive(series, func(e *Event) error {
+				inst, ok := e.Occurrence(day)
+				if !ok {
+					return ErrNoEvent
+				}
+				before = inst.clone()
+				e.Recur.setException(RecurrenceException{On: day, Skip: true})
+				return nil
+			})
+			if err != nil {
+				log.Debug("STOP EVENT occurrence failed", "id", id, "from", msg.From, "err", err)
+				g.reply(req, "ERR", "NAC")
+				return
+			}
+			g.record(msg.From, "STOP", id, &before, nil)
+			log.Info("STOP EVENT occurrence", "id", id, "from", msg.From)
+			g.reply(req, "OK", "EVENT", id)
+			return
+		}
+		before, err := g.trashEvent(id)
+		if errors.Is(err, ErrNoEvent) {
 			log.Debug("STOP EVENT NOT FOUND", "id", id, "from", msg.From)
 			g.reply(req, "ERR", "NAC")
 			return
 		}
+		if err != nil {
+			log.Error("STOP EVENT trash failed", "id", id, "from", msg.From, "err", err)
+			g.reply(req, "ERR", "STOP", err.Error())
+			return
+		}
+		g.record(msg.From, "STOP", id, &before, nil)
 		log.Info("STOP EVENT", "id", id, "from", msg.From)
 		g.reply(req, "OK", "EVENT", id)
+	case "EXCEPTION":
+		if len(msg.Args) < 1 {
+			g.reply(req, "ERR", "ARGC")
+			return
+		}
+		id := strings.TrimSpace(msg.Args[0])
+		if !g.exceptions.Delete(id) {
+			log.Debug("STOP EXCEPTION NOT FOUND", "id", id, "from", msg.From)
+			g.reply(req, "ERR", "NAC")
+			return
+		}
+		log.Info("STOP EXCEPTION", "id", id, "from", msg.From)
+		g.notifyScheduleChanged()
+		g.reply(req, "OK", "EXCEPTION", id)
 	default:
 		log.Warn("UNKNOWN NOUN", "noun", msg.Noun, "from", msg.From)
 		g.reply(req, "ERR", "NOUN")
 	}
 }
 
-func (g *Governor) Shutdown() {}
+func (g *Governor) cmdEdit(req *proto.Request) {
+	msg := req.Msg
+	switch msg.Noun {
+	case "EVENT":
+		if len(msg.Args) < 2 {
+			g.reply(req, "ERR", "ARGC")
+			return
+		}
+		id := strings.TrimSpace(msg.Args[0])
+		edits := msg.Args[1:]
+		var before, e Event
+		var err error
+		if series, day, ok := splitInstanceID(id, g.locale.location()); ok {
+			before, e, err = g.editOccurrence(series, day, edits)
+		} else {
+			e, err = g.updateLive(id, func(e *Event) error {
+				before = e.clone()
+				for _, kv := range edits {
+					field, value, ok := strings.Cut(kv, "=")
+					if !ok {
+						return replyErr{"FIELD", kv}
+					}
+					if err := applyEventEdit(e, field, value, g.locale.location()); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+		var rerr replyErr
+		switch {
+		case errors.Is(err, ErrNoEvent):
+			log.Debug("EDIT EVENT NOT FOUND", "id", id, "from", msg.From)
+			g.reply(req, "ERR", "NAC")
+			return
+		case errors.As(err, &rerr):
+			log.Warn("EDIT EVENT rejected", "id", id, "edits", edits, "from", msg.From, "err", err)
+			g.replyError(req, rerr)
+			return
+		case err != nil:
+			log.Error("EDIT EVENT update failed", "id", id, "from", msg.From, "err", err)
+			g.reply(req, "ERR", "EDIT", err.Error())
+			return
+		}
+		g.record(msg.From, "EDIT", id, &before, &e)
+		log.Info("EDIT EVENT", "id", id, "edits", edits, "from", msg.From)
+		g.reply(req, "OK", "EVENT", e.WireString())
+	default:
+		log.Warn("UNKNOWN NOUN", "noun", msg.Noun, "from", msg.From)
+		g.reply(req, "ERR", "NOUN")
+	}
+}
+
+// getSchedule answers GET:SCHEDULE:<NOW|NEXT|TODAY|TOMORROW|weekday> and
+// GET:SCHEDULE:DATE:<YYYY.MM.DD>. Trailing <column>=<value> args filter slots.
+func (g *Governor) getSchedule(req *proto.Request, arg string, rest []string) {
+	msg := req.Msg
+	now := g.now()
+	var day time.Time
+	if strings.EqualFold(arg, "DATE") {
+		if len(rest) < 1 {
+			g.reply(req, "ERR", "ARGC")
+			return
+		}
+		d, err := ParseDate(rest[0], g.locale.location())
+		if err != nil {
+			log.Warn("GET SCHEDULE bad date", "date", rest[0], "from", msg.From, "err", err)
+			g.reply(req, "ERR", "DATE", rest[0])
+			return
+		}
+		day, rest = d, rest[1:]
+	}
+	match, err := slotFilter(rest)
+	var rerr replyErr
+	if errors.As(err, &rerr) {
+		log.Warn("GET SCHEDULE bad filter", "args", rest, "from", msg.From)
+		g.replyError(req, rerr)
+		return
+	}
+
+	switch strings.ToUpper(arg) {
+	case "NOW":
+		s, end, ok := g.currentSlot(now, match)
+		if !ok {
+			log.Debug("GET SCHEDULE NOW", "slot", nil, "from", msg.From)
+			g.reply(req, "OK", "SCHEDULE")
+			return
+		}
+		left := int(end.Sub(now).Minutes())
+		log.Debug("GET SCHEDULE NOW", "slot", s.Title, "left", left, "from", msg.From)
+		g.reply(req, "OK", "SCHEDULE", s.WireString(), strconv.Itoa(left))
+		return
+	case "NEXT":
+		s, start, ok := g.nextSlot(now, match)
+		if !ok {
+			log.Debug("GET SCHEDULE NEXT", "slot", nil, "from", msg.From)
+			g.reply(req, "OK", "SCHEDULE")
+			return
+		}
+		in := int(start.Sub(now).Minutes())
+		log.Debug("GET SCHEDULE NEXT", "slot", s.Title, "in", in, "from", msg.From)
+		g.reply(req, "OK", "SCHEDULE", s.WireString(), strconv.Itoa(in))
+		return
+	case "DATE":
+	default:
+		d, ok := scheduleDay(arg, now)
+		if !ok {
+			log.Warn("GET SCHEDULE unknown day", "day", arg, "from", msg.From)
+			g.reply(req, "ERR", "WEEKDAY")
+			return
+		}
+		day = d
+	}
+	var slots []string
+	for _, s := range g.slotsOn(day) {
+		if match(s) {
+			slots = append(slots, s.WireString())
+		}
+	}
+	log.Debug("GET SCHEDULE", "day", arg, "date", day.Format("2006-01-02"), "filters", rest, "slots", len(slots), "from", msg.From)
+	g.reply(req, "OK", "SCHEDULE", slots...)
+}
+
+// editOccurrence moves one occurrence of a recurring series. Only date and
+// time can be edited per occurrence; it returns the instance before and
+// after the move.
+func (g *Governor) editOccurrence(series string, day time.Time, edits []string) (before, inst Event, err error) {
+	_, err = g.updateLive(series, func(e *Event) error {
+		cur, ok := e.Occurrence(day)
+		if !ok {
+			return ErrNoEvent
+		}
+		before = cur.clone()
+		for _, kv := range edits {
+			field, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				return replyErr{"FIELD", kv}
+			}
+			switch f := strings.ToLower(strings.TrimSpace(field)); f {
+			case "date", "time":
+				if err := applyEventEdit(&cur, f, value, g.locale.location()); err != nil {
+					return err
+				}
+			default:
+				return replyErr{"FIELD", field}
+			}
+		}
+		// Keep the rest of the exception (status, completion) as it is.
+		at := cur.At
+		ex, _ := e.Recur.exception(day)
+		ex.On, ex.At = day, &at
+		e.Recur.setException(ex)
+		inst = cur
+		return nil
+	})
+	return before, inst, err
+}
+
+// getEvent looks up a live event or, for series@date IDs, one occurrence.
+func (g *Governor) getEvent(id string) (Event, bool) {
+	series, day, isInst := splitInstanceID(id, g.locale.location())
+	e, ok := g.events.Get(series)
+	if !ok || e.Deleted() {
+		return Event{}, false
+	}
+	if isInst {
+		return e.Occurrence(day)
+	}
+	return e, true
+}
+
+// Shutdown stops background loops, waits for them to exit and closes the
+// event repository.
+func (g *Governor) Shutdown() {
+	close(g.stop)
+	g.wg.Wait()
+	if err := g.events.Close(); err != nil {
+		log.Error("EVENTS CLOSE FAILED", "err", err)
+	}
+}
@@ -0,0 +1,154 @@
+package governor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// historyWireFmt is the timestamp format of history entries on the wire.
+const historyWireFmt = "2006.01.02.15.04.05"
+
+// HistoryEntry records one event mutation: who sent it, when, and the
+// event before and after. Before is nil for a create, After for a delete.
+type HistoryEntry struct {
+	At     time.Time `json:"At"`
+	From   string    `json:"From"`
+	Verb   string    `json:"Verb"` // NEW, EDIT, DONE, CANCEL, REOPEN, STOP, RESTORE, PURGE
+	ID     string    `json:"ID"`   // event or occurrence (ev5@2026.03.06) ID
+	Before *Event    `json:"Before,omitempty"`
+	After  *Event    `json:"After,omitempty"`
+}
+
+// Format: at|from|verb|id|before|after
+// (before/after are event wire strings, escaped as one field each; empty if absent)
+func (h HistoryEntry) WireString() string {
+	var before, after string
+	if h.Before != nil {
+		before = h.Before.WireString()
+	}
+	if h.After != nil {
+		after = h.After.WireString()
+	}
+	return proto.JoinFields(h.At.Format(historyWireFmt), h.From, h.Verb, h.ID, before, after)
+}
+
+// concerns reports whether h is about id; a series ID also matches entries
+// for its occurrences.
+func (h HistoryEntry) concerns(id string) bool {
+	return h.ID == id || strings.HasPrefix(h.ID, id+instanceSep)
+}
+
+// historyMemoryMax is roughly how many of the latest history entries are
+// kept in memory (and so served by GET:HISTORY); the file keeps them all.
+const historyMemoryMax = 10000
+
+// historyLog is the audit trail, kept in memory (at least the latest
+// historyMemoryMax entries) and appended as JSON lines to path (if set),
+// which is never rewritten.
+type historyLog struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	path    string
+}
+
+func newHistoryLog(path string) (*historyLog, error) {
+	h := &historyLog{path: path}
+	if path == "" {
+		return h, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open history file %s: %w", path, err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		var e HistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			slog.Warn("history load: skipping bad line", "path", path, "line", line, "err", err)
+			continue
+		}
+		h.entries = append(h.entries, e)
+		h.trim()
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read history file %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Append records e. A failed write is logged; the entry is still kept
+// in memory.
+func (h *historyLog) Append(e HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, e)
+	h.trim()
+	if h.path == "" {
+		return
+	}
+	if err := h.write(e); err != nil {
+		slog.Error("history write failed", "path", h.path, "id", e.ID, "err", err)
+	}
+}
+
+// trim drops the oldest entries beyond historyMemoryMax. It lets the slice
+// overshoot by a quarter so the copy happens once per many appends.
+func (h *historyLog) trim() {
+	if len(h.entries) <= historyMemoryMax+historyMemoryMax/4 {
+		return
+	}
+	h.entries = slices.Clone(h.entries[len(h.entries)-historyMemoryMax:])
+}
+
+func (h *historyLog) write(e HistoryEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// List returns entries in the order they happened; with a non-empty id,
+// only those about that event (see HistoryEntry.concerns).
+func (h *historyLog) List(id string) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []HistoryEntry
+	for _, e := range h.entries {
+		if id == "" || e.concerns(id) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// record adds an audit entry for a mutation requested by from.
+func (g *Governor) record(from, verb, id string, before, after *Event) {
+	g.history.Append(HistoryEntry{At: g.now(), From: from, Verb: verb, ID: id, Before: before, After: after})
+}
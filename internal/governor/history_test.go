@@ -0,0 +1,44 @@
+package governor
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHistoryMemoryCap(t *testing.T) {
+	h, _ := newHistoryLog("")
+	n := historyMemoryMax*2 + 7
+	for i := range n {
+		h.Append(HistoryEntry{At: time.Now(), Verb: "NEW", ID: fmt.Sprintf("ev%d", i)})
+	}
+	all := h.List("")
+	if len(all) < historyMemoryMax || len(all) > historyMemoryMax+historyMemoryMax/4 {
+		t.Fatalf("kept %d entries, want about %d", len(all), historyMemoryMax)
+	}
+	if last := all[len(all)-1].ID; last != fmt.Sprintf("ev%d", n-1) {
+		t.Fatalf("newest entry is %s", last)
+	}
+}
+
+func TestHistoryPaging(t *testing.T) {
+	entries := make([]HistoryEntry, 5)
+	for i := range entries {
+		entries[i] = HistoryEntry{Verb: "NEW", ID: fmt.Sprintf("ev%d", i+1)}
+	}
+	q, rest, err := parsePageQuery([]string{"ev1", "offset=1", "limit=2"})
+	if err != nil || !slices.Equal(rest, []string{"ev1"}) {
+		t.Fatalf("parsePageQuery = %v, %v", rest, err)
+	}
+	args := pageItems(q, entries, nil, HistoryEntry.WireString)
+	want := []string{entries[1].WireString(), entries[2].WireString(), "next=3"}
+	if !slices.Equal(args, want) {
+		t.Fatalf("page = %q, want %q", args, want)
+	}
+	for _, bad := range []string{"sort=title", "limit=-1", "offset=x"} {
+		if _, _, err := parsePageQuery([]string{bad}); err == nil {
+			t.Errorf("parsePageQuery(%q): want error", bad)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package governor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Journal operations.
+const (
+	journalPut = "put" // Event is the full new state of ID
+	journalDel = "del" // ID was deleted
+)
+
+// journalCompactEvery is how many journal records accumulate before the
+// store folds them into a fresh snapshot and truncates the journal.
+const journalCompactEvery = 200
+
+// journalRecord is one line of the journal. Records carry whole states, not
+// diffs, so replaying one that the snapshot already contains is harmless.
+type journalRecord struct {
+	Op    string `json:"Op"`
+	ID    string `json:"ID"`
+	Event *Event `json:"Event,omitempty"`
+}
+
+// journal is an append-only JSON-lines log of store mutations. It is not
+// safe for concurrent use; the owning store serializes access.
+type journal struct {
+	path string
+	f    *os.File
+	n    int // records since the last reset
+}
+
+func openJournal(path string) (*journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{path: path, f: f}, nil
+}
+
+// replay calls fn with every record in order and leaves the file positioned
+// for appending. A torn last record (crash mid-append) is dropped with a
+// warning and cut off; a bad record before the end is an error, since
+// skipping it would reorder history.
+func (j *journal) replay(fn func(journalRecord)) error {
+	if _, err := j.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(j.f)
+	if err != nil {
+		return err
+	}
+	var good int64 // bytes of valid records
+	for line := 1; len(data) > 0; line++ {
+		raw, rest, complete := bytes.Cut(data, []byte("\n"))
+		var rec journalRecord
+		if err := json.Unmarshal(raw, &rec); err != nil || rec.ID == "" {
+			if len(bytes.TrimSpace(rest)) > 0 {
+				return fmt.Errorf("journal %s line %d: bad record", j.path, line)
+			}
+			slog.Warn("journal: dropping torn last record", "path", j.path, "line", line)
+			break
+		}
+		if !complete {
+			// Parsed but never got its newline: keep it, and finish the line.
+			if _, err := j.f.Write([]byte("\n")); err != nil {
+				return err
+			}
+		}
+		fn(rec)
+		j.n++
+		good += int64(len(raw)) + 1
+		data = rest
+	}
+	return j.truncate(good)
+}
+
+// truncate cuts the file to size bytes and moves the write offset there.
+func (j *journal) truncate(size int64) error {
+	if err := j.f.Truncate(size); err != nil {
+		return err
+	}
+	_, err := j.f.Seek(size, io.SeekStart)
+	return err
+}
+
+// append writes rec and syncs it to disk before returning. On failure the
+// partial line is cut off so the next record starts clean.
+func (j *journal) append(rec journalRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	off, err := j.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(data, '\n')); err != nil {
+		j.truncate(off)
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		j.truncate(off)
+		return err
+	}
+	j.n++
+	return nil
+}
+
+// reset empties the journal once its records are in a snapshot.
+func (j *journal) reset() error {
+	if err := j.truncate(0); err != nil {
+		return err
+	}
+	j.n = 0
+	return j.f.Sync()
+}
+
+func (j *journal) close() error { return j.f.Close() }
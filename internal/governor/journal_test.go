@@ -0,0 +1,159 @@
+package governor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// journalLine is rec as the journal writes it, newline included.
+func journalLine(t *testing.T, rec journalRecord) string {
+	t.Helper()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+func TestJournalReplay(t *testing.T) {
+	put1 := journalLine(t, journalRecord{Op: journalPut, ID: "ev1", Event: &Event{Title: "Lab"}})
+	put2 := journalLine(t, journalRecord{Op: journalPut, ID: "ev2", Event: &Event{Title: "Exam"}})
+	del1 := journalLine(t, journalRecord{Op: journalDel, ID: "ev1"})
+
+	tests := []struct {
+		name     string
+		content  string
+		wantIDs  []string // records replayed, in order
+		wantFile string   // journal contents afterwards
+	}{
+		{
+			name:     "clean",
+			content:  put1 + put2 + del1,
+			wantIDs:  []string{"ev1", "ev2", "ev1"},
+			wantFile: put1 + put2 + del1,
+		},
+		{
+			name:     "torn last line",
+			content:  put1 + put2 + del1[:len(del1)/2],
+			wantIDs:  []string{"ev1", "ev2"},
+			wantFile: put1 + put2,
+		},
+		{
+			name:     "torn last line with newline",
+			content:  put1 + `{"Op":"put","ID":` + "\n",
+			wantIDs:  []string{"ev1"},
+			wantFile: put1,
+		},
+		{
+			name:     "last record without newline",
+			content:  put1 + strings.TrimSuffix(put2, "\n"),
+			wantIDs:  []string{"ev1", "ev2"},
+			wantFile: put1 + put2,
+		},
+		{
+			name:     "empty",
+			content:  "",
+			wantIDs:  nil,
+			wantFile: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "events.json.journal")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			j, err := openJournal(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer j.close()
+
+			var ids []string
+			if err := j.replay(func(rec journalRecord) { ids = append(ids, rec.ID) }); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ids, tt.wantIDs) || j.n != len(tt.wantIDs) {
+				t.Errorf("replayed %q (n=%d), want %q", ids, j.n, tt.wantIDs)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.wantFile {
+				t.Errorf("file after replay = %q, want %q", got, tt.wantFile)
+			}
+
+			// The next append must start on a line of its own.
+			if err := j.append(journalRecord{Op: journalDel, ID: "ev9"}); err != nil {
+				t.Fatal(err)
+			}
+			want := tt.wantFile + journalLine(t, journalRecord{Op: journalDel, ID: "ev9"})
+			if got, _ := os.ReadFile(path); string(got) != want {
+				t.Errorf("file after append = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestJournalReplayCorruptMiddle(t *testing.T) {
+	put1 := journalLine(t, journalRecord{Op: journalPut, ID: "ev1", Event: &Event{Title: "Lab"}})
+	put2 := journalLine(t, journalRecord{Op: journalPut, ID: "ev2", Event: &Event{Title: "Exam"}})
+	content := put1 + "not json\n" + put2
+
+	path := filepath.Join(t.TempDir(), "events.json.journal")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	j, err := openJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+	if err := j.replay(func(journalRecord) {}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("replay err = %v, want a bad record on line 2", err)
+	}
+	// Nothing is cut off: the operator has to look at it.
+	if got, _ := os.ReadFile(path); string(got) != content {
+		t.Errorf("file changed to %q", got)
+	}
+}
+
+func TestEventStoreReplaysJournal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.json")
+	if err := os.WriteFile(path, []byte(`[{"ID":"ev1","Title":"Lab"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	journal := journalLine(t, journalRecord{Op: journalPut, ID: "ev2", Event: &Event{Title: "Exam"}}) +
+		journalLine(t, journalRecord{Op: journalPut, ID: "ev1", Event: &Event{Title: "Lab 2"}}) +
+		journalLine(t, journalRecord{Op: journalDel, ID: "ev2"}) +
+		journalLine(t, journalRecord{Op: journalPut, ID: "ev3", Event: &Event{Title: "Quiz"}}) +
+		`{"Op":"put","ID":"ev4","Ev` // torn
+	if err := os.WriteFile(path+".journal", []byte(journal), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewJSONEventRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var got []string
+	for _, e := range r.List() {
+		got = append(got, e.ID+"="+e.Title)
+	}
+	slices.Sort(got)
+	if want := []string{"ev1=Lab 2", "ev3=Quiz"}; !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+	// The replayed records were folded into the snapshot.
+	if data, _ := os.ReadFile(path + ".journal"); len(data) != 0 {
+		t.Errorf("journal not emptied after replay: %q", data)
+	}
+	// IDs seen only in the journal are not handed out again.
+	id, err := r.Add(Event{Title: "New"})
+	if err != nil || parseEventID(id) <= 3 {
+		t.Errorf("Add after replay = %q, %v; want an ID past ev3", id, err)
+	}
+}
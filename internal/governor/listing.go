@@ -0,0 +1,203 @@
+package governor
+
+import (
+	"cmp"
+	"errors"
+	log "log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// Sort keys for event listings.
+const (
+	SortDeadline = "deadline"
+	SortCreated  = "created"
+	SortTitle    = "title"
+)
+
+// listQuery holds the key=value args of a listing command: the shared
+// EventFilter conditions plus ordering and paging:
+//
+//	sort=<deadline|created|title>  order=<asc|desc>
+//	offset=<n>  limit=<n>
+//
+// Positional args are left to the command.
+type listQuery struct {
+	filter EventFilter
+	sortBy string // "" = the command's own order
+	desc   bool
+	offset int
+	limit  int // 0 = no limit
+}
+
+// parseListQuery splits args into options and positional args. defStatus is
+// used when no status= is given; dates are read in loc.
+func parseListQuery(args []string, defStatus string, loc *time.Location) (listQuery, []string, error) {
+	q := listQuery{filter: EventFilter{Status: defStatus}}
+	var rest []string
+	for _, a := range args {
+		key, val, ok := strings.Cut(a, "=")
+		if !ok {
+			rest = append(rest, a)
+			continue
+		}
+		if known, err := q.filter.Set(key, val, loc); err != nil {
+			return listQuery{}, nil, err
+		} else if known {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+		switch key {
+		case "sort":
+			switch q.sortBy = strings.ToLower(val); q.sortBy {
+			case SortDeadline, SortCreated, SortTitle:
+			default:
+				return listQuery{}, nil, replyErr{"SORT", val}
+			}
+		case "order":
+			switch strings.ToLower(val) {
+			case "asc":
+				q.desc = false
+			case "desc":
+				q.desc = true
+			default:
+				return listQuery{}, nil, replyErr{"ORDER", val}
+			}
+		case "offset", "limit":
+			if err := q.setPage(key, val); err != nil {
+				return listQuery{}, nil, err
+			}
+		default:
+			return listQuery{}, nil, replyErr{"FILTER", key}
+		}
+	}
+	return q, rest, nil
+}
+
+// parsePageQuery is parseListQuery for listings that are not events
+// (history): only offset= and limit= are accepted.
+func parsePageQuery(args []string) (listQuery, []string, error) {
+	var q listQuery
+	var rest []string
+	for _, a := range args {
+		key, val, ok := strings.Cut(a, "=")
+		if !ok {
+			rest = append(rest, a)
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "offset" && key != "limit" {
+			return listQuery{}, nil, replyErr{"FILTER", key}
+		}
+		if err := q.setPage(key, strings.TrimSpace(val)); err != nil {
+			return listQuery{}, nil, err
+		}
+	}
+	return q, rest, nil
+}
+
+// setPage sets offset or limit from a non-negative number.
+func (q *listQuery) setPage(key, val string) error {
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return replyErr{"PAGE", key + "=" + val}
+	}
+	if key == "offset" {
+		q.offset = n
+	} else {
+		q.limit = n
+	}
+	return nil
+}
+
+// parseListArgs is parseListQuery on a request's args; on bad args it
+// replies with the error and returns false.
+func (g *Governor) parseListArgs(req *proto.Request, defStatus string) (listQuery, []string, bool) {
+	q, rest, err := parseListQuery(req.Msg.Args, defStatus, g.locale.location())
+	var rerr replyErr
+	if errors.As(err, &rerr) {
+		log.Warn("GET "+req.Msg.Noun+" bad args", "args", req.Msg.Args, "from", req.Msg.From, "err", err)
+		g.replyError(req, rerr)
+		return listQuery{}, nil, false
+	}
+	return q, rest, true
+}
+
+// match returns a predicate for the query's filter, with overdue judged
+// at now.
+func (q listQuery) match(now time.Time) func(Event) bool {
+	return func(e Event) bool { return q.filter.Match(e, now) }
+}
+
+// pageArgs sorts events (by deadline unless sort= says otherwise) and
+// renders the requested page as reply args.
+func (q listQuery) pageArgs(events []Event) []string {
+	q.sortBy = cmp.Or(q.sortBy, SortDeadline)
+	return pageItems(q, events, func(e Event) Event { return e }, Event.WireString)
+}
+
+// pageItems renders one page of items as reply args, ending with
+// next=<offset> when more items follow. Items are re-sorted only if the
+// query names a sort key; otherwise the caller's order is kept and event
+// may be nil.
+func pageItems[T any](q listQuery, items []T, event func(T) Event, wire func(T) string) []string {
+	if q.sortBy != "" {
+		slices.SortStableFunc(items, func(a, b T) int {
+			if q.desc {
+				return compareEvents(event(b), event(a), q.sortBy)
+			}
+			return compareEvents(event(a), event(b), q.sortBy)
+		})
+	}
+	next := -1
+	if q.offset >= len(items) {
+		items = nil
+	} else {
+		items = items[q.offset:]
+		if q.limit > 0 && q.limit < len(items) {
+			items, next = items[:q.limit], q.offset+q.limit
+		}
+	}
+	args := make([]string, 0, len(items)+1)
+	for _, it := range items {
+		args = append(args, wire(it))
+	}
+	if next >= 0 {
+		args = append(args, "next="+strconv.Itoa(next))
+	}
+	return args
+}
+
+// compareEvents orders two events by key; ties are broken by deadline and
+// then ID, so the order is the same on every call.
+func compareEvents(a, b Event, key string) int {
+	switch key {
+	case SortTitle:
+		if c := strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)); c != 0 {
+			return c
+		}
+	case SortCreated:
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		if c := seriesNumber(a.ID) - seriesNumber(b.ID); c != 0 {
+			return c
+		}
+	}
+	if c := a.At.Compare(b.At); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// seriesNumber is the number of an event ID, or of the series an
+// occurrence ID belongs to.
+func seriesNumber(id string) int {
+	series, _, _ := strings.Cut(id, instanceSep)
+	return parseEventID(series)
+}
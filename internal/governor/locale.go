@@ -0,0 +1,99 @@
+package governor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Output languages.
+const (
+	LangEN = "en"
+	LangRU = "ru"
+)
+
+// Locale holds the calendar conventions used for schedule lookups and
+// periods. Input accepts English and Russian names whatever Lang is; Lang
+// picks the weekday names governor writes. Dates and clock times in
+// requests and the schedule are read in Zone, and "now" is taken there.
+type Locale struct {
+	WeekStart time.Weekday   // first day of a week: week periods, teaching weeks
+	Lang      string         // LangEN or LangRU
+	Zone      *time.Location // nil = time.Local
+}
+
+// DefaultLocale starts weeks on Monday, answers in English and uses the
+// system time zone.
+var DefaultLocale = Locale{WeekStart: time.Monday, Lang: LangEN}
+
+// weekdayNames maps lowercase weekday names, short and full, English and
+// Russian, to their weekday.
+var weekdayNames = map[string]time.Weekday{}
+
+// shortWeekdays are the output names per language, indexed by time.Weekday.
+var shortWeekdays = map[string][7]string{
+	LangEN: {"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	LangRU: {"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+}
+
+func init() {
+	full := [][7]string{
+		{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"},
+		{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	}
+	for _, names := range append(full, shortWeekdays[LangEN], shortWeekdays[LangRU]) {
+		for wd, name := range names {
+			weekdayNames[strings.ToLower(name)] = time.Weekday(wd)
+		}
+	}
+}
+
+// ParseWeekday accepts short or full English and Russian names in any
+// case (Mon, MON, Monday, Пн, понедельник).
+func ParseWeekday(s string) (time.Weekday, bool) {
+	wd, ok := weekdayNames[strings.ToLower(strings.TrimSpace(s))]
+	return wd, ok
+}
+
+// ParseLang accepts en or ru in any case.
+func ParseLang(s string) (string, error) {
+	lang := strings.ToLower(strings.TrimSpace(s))
+	if _, ok := shortWeekdays[lang]; !ok {
+		return "", fmt.Errorf("unknown language %q (want %s or %s)", s, LangEN, LangRU)
+	}
+	return lang, nil
+}
+
+// location returns the zone dates are read in.
+func (l Locale) location() *time.Location {
+	if l.Zone == nil {
+		return time.Local
+	}
+	return l.Zone
+}
+
+// now is the current time in the locale's zone, so that midnights and
+// weekdays derived from it are local to the user, not the host.
+func (g *Governor) now() time.Time {
+	return time.Now().In(g.locale.location())
+}
+
+// WeekdayName returns the short name of wd in the locale's language.
+func (l Locale) WeekdayName(wd time.Weekday) string {
+	names, ok := shortWeekdays[l.Lang]
+	if !ok {
+		names = shortWeekdays[LangEN]
+	}
+	return names[wd]
+}
+
+// startOfWeek returns midnight of the first day of t's week.
+func startOfWeek(t time.Time, first time.Weekday) time.Time {
+	d := startOfDay(t)
+	return d.AddDate(0, 0, -((int(d.Weekday()) - int(first) + 7) % 7))
+}
+
+// WithLocale sets the week start, output language and time zone.
+func WithLocale(l Locale) Option {
+	return func(g *Governor) { g.locale = l }
+}
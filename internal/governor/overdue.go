@@ -0,0 +1,50 @@
+package governor
+
+import (
+	"sort"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// StatusOverdue is a derived state, never stored: an open event whose
+// deadline plus grace has passed. It can be used as a status= filter.
+const StatusOverdue = "overdue"
+
+// Due returns when e becomes overdue: its deadline plus the grace period.
+func (e Event) Due() time.Time { return e.At.Add(e.Grace) }
+
+// Overdue reports whether e is still open at now although past Due.
+func (e Event) Overdue(now time.Time) bool {
+	return e.State() == StatusOpen && now.After(e.Due())
+}
+
+// overdueItem is one overdue event or occurrence and how late it is.
+type overdueItem struct {
+	Event
+	late time.Duration // since At, not since Due
+}
+
+// Format: <event fields...>|<late> (late e.g. 26h5m0s, from the deadline)
+func (o overdueItem) WireString() string {
+	return o.Event.WireString() + proto.FieldSep + o.late.String()
+}
+
+// overdue returns every overdue event and occurrence at now, latest first.
+func (g *Governor) overdue(now time.Time) []overdueItem {
+	var out []overdueItem
+	for _, e := range g.liveEvents() {
+		for _, occ := range e.Occurrences(time.Time{}, now) {
+			if occ.Overdue(now) {
+				out = append(out, overdueItem{occ, now.Sub(occ.At).Truncate(time.Minute)})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].late != out[j].late {
+			return out[i].late > out[j].late
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
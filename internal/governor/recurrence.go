@@ -0,0 +1,337 @@
+package governor
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// maxRecurrenceSteps bounds expansion so a degenerate rule can't spin forever.
+const maxRecurrenceSteps = 100000
+
+// instanceSep joins a series ID and an occurrence date: ev5@2026.03.06.
+const instanceSep = "@"
+
+// Recurrence is an RRULE-style repeat rule. The first occurrence is the
+// event's At; later ones keep its clock time.
+type Recurrence struct {
+	Freq       string                `json:"Freq"`                 // DAILY, WEEKLY, MONTHLY
+	Interval   int                   `json:"Interval,omitempty"`   // every N days/weeks/months; 0 = 1
+	Count      int                   `json:"Count,omitempty"`      // total occurrences; 0 = unlimited
+	Until      *time.Time            `json:"Until,omitempty"`      // last date (inclusive); nil = unlimited
+	ByWeekday  []time.Weekday        `json:"ByWeekday,omitempty"`  // DAILY/WEEKLY only; empty = At's weekday
+	Exceptions []RecurrenceException `json:"Exceptions,omitempty"` // per-occurrence skips and moves
+}
+
+// RecurrenceException overrides one occurrence, identified by its original date.
+type RecurrenceException struct {
+	On          time.Time  `json:"On"`                    // original occurrence date (midnight)
+	Skip        bool       `json:"Skip,omitempty"`        // occurrence is dropped
+	At          *time.Time `json:"At,omitempty"`          // occurrence is moved here
+	Status      string     `json:"Status,omitempty"`      // occurrence is done or cancelled (overrides the series)
+	CompletedAt *time.Time `json:"CompletedAt,omitempty"` // when Status was set
+}
+
+// apply overrides inst (an occurrence) with the exception; false if skipped.
+func (ex RecurrenceException) apply(inst *Event) bool {
+	if ex.Skip {
+		return false
+	}
+	if ex.At != nil {
+		inst.At = *ex.At
+	}
+	if ex.Status != "" {
+		inst.Status, inst.CompletedAt = ex.Status, ex.CompletedAt
+	}
+	return true
+}
+
+var rruleDays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// ParseRecurrence parses a rule like FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10;UNTIL=2026.06.01.
+// Returns nil for an empty string or "none".
+// UNTIL is a date in loc.
+func ParseRecurrence(s string, loc *time.Location) (*Recurrence, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "none") {
+		return nil, nil
+	}
+	r := &Recurrence{}
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("rule part %q: need KEY=VALUE", part)
+		}
+		val = strings.TrimSpace(val)
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "FREQ":
+			switch f := strings.ToUpper(val); f {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				r.Freq = f
+			default:
+				return nil, fmt.Errorf("freq must be DAILY, WEEKLY or MONTHLY, got %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("interval must be a positive number, got %q", val)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("count must be a positive number, got %q", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := ParseDate(val, loc)
+			if err != nil {
+				return nil, fmt.Errorf("until: %w", err)
+			}
+			r.Until = &t
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := rruleDays[strings.ToUpper(strings.TrimSpace(d))]
+				if !ok {
+					return nil, fmt.Errorf("byday: unknown day %q", d)
+				}
+				if !slices.Contains(r.ByWeekday, wd) {
+					r.ByWeekday = append(r.ByWeekday, wd)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule part %q", key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("rule needs FREQ")
+	}
+	if r.Freq == FreqMonthly && len(r.ByWeekday) > 0 {
+		return nil, fmt.Errorf("byday is only supported with DAILY and WEEKLY")
+	}
+	return r, nil
+}
+
+// String formats the rule (without exceptions) in ParseRecurrence syntax.
+func (r *Recurrence) String() string {
+	if r == nil {
+		return ""
+	}
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByWeekday) > 0 {
+		days := make([]string, len(r.ByWeekday))
+		for i, wd := range r.ByWeekday {
+			days[i] = strings.ToUpper(wd.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("2006.01.02"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// each calls fn with every base occurrence (before exceptions) in order,
+// starting at start, until fn returns false or the rule is exhausted.
+func (r *Recurrence) each(start time.Time, fn func(time.Time) bool) {
+	interval := max(r.Interval, 1)
+	var untilEnd time.Time
+	if r.Until != nil {
+		untilEnd = r.Until.AddDate(0, 0, 1)
+	}
+	n := 0
+	emit := func(t time.Time) bool {
+		if !untilEnd.IsZero() && !t.Before(untilEnd) {
+			return false
+		}
+		n++
+		if !fn(t) {
+			return false
+		}
+		return r.Count == 0 || n < r.Count
+	}
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	day0 := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	switch r.Freq {
+	case FreqDaily:
+		for i := 0; i < maxRecurrenceSteps; i++ {
+			t := at(day0.AddDate(0, 0, i*interval))
+			if len(r.ByWeekday) > 0 && !slices.Contains(r.ByWeekday, t.Weekday()) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	case FreqWeekly:
+		days := r.ByWeekday
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		offsets := make([]int, len(days))
+		for i, wd := range days {
+			offsets[i] = (int(wd) + 6) % 7 // days since Monday
+		}
+		sort.Ints(offsets)
+		monday := day0.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for w := 0; w < maxRecurrenceSteps; w += interval {
+			for _, off := range offsets {
+				t := at(monday.AddDate(0, 0, w*7+off))
+				if t.Before(start) {
+					continue
+				}
+				if !emit(t) {
+					return
+				}
+			}
+		}
+	case FreqMonthly:
+		for m := 0; m < maxRecurrenceSteps; m += interval {
+			t := time.Date(start.Year(), start.Month()+time.Month(m), start.Day(),
+				start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+			if t.Day() != start.Day() {
+				continue // month too short (e.g. 31st)
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// exception returns the override for the occurrence originally on day.
+func (r *Recurrence) exception(day time.Time) (RecurrenceException, bool) {
+	key := day.Format("2006.01.02")
+	for _, ex := range r.Exceptions {
+		if ex.On.Format("2006.01.02") == key {
+			return ex, true
+		}
+	}
+	return RecurrenceException{}, false
+}
+
+// setException adds or replaces the override for ex.On.
+func (r *Recurrence) setException(ex RecurrenceException) {
+	key := ex.On.Format("2006.01.02")
+	for i := range r.Exceptions {
+		if r.Exceptions[i].On.Format("2006.01.02") == key {
+			r.Exceptions[i] = ex
+			return
+		}
+	}
+	r.Exceptions = append(r.Exceptions, ex)
+}
+
+// instance builds the concrete occurrence of series e originally at t.
+func (e Event) instance(t time.Time) Event {
+	inst := e
+	inst.ID = e.ID + instanceSep + t.Format("2006.01.02")
+	inst.At = t
+	if e.VisibleFrom != nil {
+		vf := t.Add(-e.At.Sub(*e.VisibleFrom))
+		inst.VisibleFrom = &vf
+	}
+	return inst
+}
+
+// Occurrences returns the concrete instances of e whose time falls in
+// [from, to], with exceptions applied, ordered by At. A non-recurring event
+// yields itself if At is in range. The series is expanded in from's zone,
+// so occurrences keep their wall-clock time across DST changes there.
+func (e Event) Occurrences(from, to time.Time) []Event {
+	if e.Recur == nil {
+		if !e.At.Before(from) && !e.At.After(to) {
+			return []Event{e}
+		}
+		return nil
+	}
+	// Moved occurrences may land in range from an original date outside it,
+	// so walk far enough to see every exception.
+	limit := to
+	for _, ex := range e.Recur.Exceptions {
+		if end := ex.On.AddDate(0, 0, 1); end.After(limit) {
+			limit = end
+		}
+	}
+	var out []Event
+	e.Recur.each(e.At.In(from.Location()), func(t time.Time) bool {
+		if t.After(limit) {
+			return false
+		}
+		inst := e.instance(t)
+		if ex, ok := e.Recur.exception(t); ok && !ex.apply(&inst) {
+			return true
+		}
+		if !inst.At.Before(from) && !inst.At.After(to) {
+			out = append(out, inst)
+		}
+		return true
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// Occurrence returns the instance of series e originally on the given date,
+// with exceptions applied, expanded in day's zone. ok is false if the rule
+// has no occurrence that day or it was skipped.
+func (e Event) Occurrence(day time.Time) (inst Event, ok bool) {
+	if e.Recur == nil {
+		return Event{}, false
+	}
+	key := day.Format("2006.01.02")
+	e.Recur.each(e.At.In(day.Location()), func(t time.Time) bool {
+		if k := t.Format("2006.01.02"); k < key {
+			return true
+		} else if k > key {
+			return false
+		}
+		inst, ok = e.instance(t), true
+		return false
+	})
+	if !ok {
+		return Event{}, false
+	}
+	if ex, found := e.Recur.exception(inst.At); found && !ex.apply(&inst) {
+		return Event{}, false
+	}
+	return inst, true
+}
+
+// splitInstanceID splits "ev5@2026.03.06" into the series ID and date
+// (midnight in loc). ok is false for plain IDs.
+func splitInstanceID(id string, loc *time.Location) (series string, day time.Time, ok bool) {
+	series, date, found := strings.Cut(id, instanceSep)
+	if !found {
+		return id, time.Time{}, false
+	}
+	day, err := ParseDate(date, loc)
+	if err != nil {
+		return id, time.Time{}, false
+	}
+	return series, day, true
+}
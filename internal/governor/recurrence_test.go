@@ -0,0 +1,202 @@
+package governor
+
+import (
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Berlin on hosts without a zone database
+)
+
+// on is a local time on 2026-<m>-<d>.
+func on(m time.Month, d, h, min int) time.Time {
+	return time.Date(2026, m, d, h, min, 0, 0, time.Local)
+}
+
+// dates formats occurrences as YYYY.MM.DD.HH.MM for comparison.
+func dates(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.At.Format("2006.01.02.15.04")
+	}
+	return out
+}
+
+func TestOccurrences(t *testing.T) {
+	until := on(time.March, 16, 0, 0)
+	tests := []struct {
+		name     string
+		start    time.Time
+		rule     string
+		from, to time.Time
+		want     []string
+	}{
+		{
+			name:  "weekly byday count",
+			start: on(time.March, 4, 9, 0), // Wednesday
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=5",
+			from:  on(time.March, 1, 0, 0), to: on(time.April, 30, 0, 0),
+			// Monday the 2nd is before the start and doesn't count.
+			want: []string{"2026.03.04.09.00", "2026.03.06.09.00", "2026.03.09.09.00", "2026.03.11.09.00", "2026.03.13.09.00"},
+		},
+		{
+			name:  "weekly interval",
+			start: on(time.March, 2, 18, 30),
+			rule:  "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			from:  on(time.March, 1, 0, 0), to: on(time.May, 1, 0, 0),
+			want: []string{"2026.03.02.18.30", "2026.03.16.18.30", "2026.03.30.18.30"},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			start: on(time.January, 31, 12, 0),
+			rule:  "FREQ=MONTHLY;COUNT=4",
+			from:  on(time.January, 1, 0, 0), to: time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local),
+			want: []string{"2026.01.31.12.00", "2026.03.31.12.00", "2026.05.31.12.00", "2026.07.31.12.00"},
+		},
+		{
+			name:  "until is inclusive",
+			start: on(time.March, 9, 23, 59),
+			rule:  "FREQ=DAILY;INTERVAL=7;UNTIL=2026.03.16",
+			from:  on(time.March, 1, 0, 0), to: on(time.April, 30, 0, 0),
+			want: []string{"2026.03.09.23.59", "2026.03.16.23.59"},
+		},
+		{
+			name:  "daily byday",
+			start: on(time.March, 6, 8, 0), // Friday
+			rule:  "FREQ=DAILY;BYDAY=MO,FR",
+			from:  on(time.March, 1, 0, 0), to: until,
+			want: []string{"2026.03.06.08.00", "2026.03.09.08.00", "2026.03.13.08.00"},
+		},
+		{
+			name:  "window cuts the series",
+			start: on(time.March, 2, 10, 0),
+			rule:  "FREQ=DAILY",
+			from:  on(time.March, 5, 0, 0), to: on(time.March, 7, 10, 0),
+			want: []string{"2026.03.05.10.00", "2026.03.06.10.00", "2026.03.07.10.00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.rule, time.Local)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := Event{ID: "ev1", At: tt.start, Recur: r}
+			if got := dates(e.Occurrences(tt.from, tt.to)); !slices.Equal(got, tt.want) {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrencesExceptions(t *testing.T) {
+	r, err := ParseRecurrence("FREQ=WEEKLY;COUNT=4", time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved := on(time.April, 20, 14, 0) // far outside the original dates
+	r.Exceptions = []RecurrenceException{
+		{On: on(time.March, 9, 0, 0), Skip: true},
+		{On: on(time.March, 16, 0, 0), At: &moved},
+	}
+	e := Event{ID: "ev1", At: on(time.March, 2, 10, 0), Recur: r}
+
+	// The skipped date still counts towards COUNT.
+	want := []string{"2026.03.02.10.00", "2026.03.23.10.00", "2026.04.20.14.00"}
+	if got := dates(e.Occurrences(on(time.March, 1, 0, 0), on(time.May, 1, 0, 0))); !slices.Equal(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	// A move lands in a window that doesn't contain its original date.
+	got := e.Occurrences(on(time.April, 20, 0, 0), on(time.April, 21, 0, 0))
+	if len(got) != 1 || !got[0].At.Equal(moved) || got[0].ID != "ev1@2026.03.16" {
+		t.Errorf("moved occurrence: got %+v", got)
+	}
+	if _, ok := e.Occurrence(on(time.March, 9, 0, 0)); ok {
+		t.Error("skipped occurrence found")
+	}
+	if inst, ok := e.Occurrence(on(time.March, 16, 0, 0)); !ok || !inst.At.Equal(moved) {
+		t.Errorf("Occurrence of the moved date = %+v, %v", inst, ok)
+	}
+}
+
+func TestOccurrencesAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reloaded from JSON, At has a fixed +01:00 offset, not the zone.
+	start := time.Date(2026, time.March, 25, 10, 0, 0, 0, time.FixedZone("", 60*60))
+	r, _ := ParseRecurrence("FREQ=WEEKLY;COUNT=2", berlin)
+	e := Event{ID: "ev1", At: start, Recur: r}
+
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, berlin)
+	got := e.Occurrences(from, from.AddDate(0, 2, 0))
+	// Clocks go forward on March 29; the class stays at 10:00 local.
+	want := time.Date(2026, time.April, 1, 10, 0, 0, 0, berlin)
+	if len(got) != 2 || !got[1].At.Equal(want) {
+		t.Fatalf("got %v, want the second at %v", dates(got), want)
+	}
+	if inst, ok := e.Occurrence(time.Date(2026, time.April, 1, 0, 0, 0, 0, berlin)); !ok || !inst.At.Equal(want) {
+		t.Errorf("Occurrence = %v, %v; want %v", inst.At, ok, want)
+	}
+}
+
+func TestOccurrenceByID(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,TH", time.Local)
+	e := Event{ID: "ev5", At: on(time.March, 5, 9, 0), Recur: r} // Thursday
+
+	tests := []struct {
+		id   string
+		ok   bool
+		want time.Time
+	}{
+		{"ev5@2026.03.05", true, on(time.March, 5, 9, 0)},
+		{"ev5@2026.03.09", true, on(time.March, 9, 9, 0)},
+		{"ev5@2026.03.02", false, time.Time{}}, // a Monday before the series starts
+		{"ev5@2026.02.26", false, time.Time{}}, // a Thursday before the series starts
+		{"ev5@2026.03.10", false, time.Time{}}, // not a rule day
+	}
+	for _, tt := range tests {
+		series, day, ok := splitInstanceID(tt.id, time.Local)
+		if !ok || series != "ev5" {
+			t.Fatalf("splitInstanceID(%q) = %q, %v, %v", tt.id, series, day, ok)
+		}
+		inst, ok := e.Occurrence(day)
+		if ok != tt.ok || (ok && (!inst.At.Equal(tt.want) || inst.ID != tt.id)) {
+			t.Errorf("Occurrence(%s) = %s %v, %v; want %v, %v", tt.id, inst.ID, inst.At, ok, tt.want, tt.ok)
+		}
+	}
+
+	for _, id := range []string{"ev5", "ev5@", "ev5@2026.13.01", "ev5@soon"} {
+		if series, _, ok := splitInstanceID(id, time.Local); ok || series != id {
+			t.Errorf("splitInstanceID(%q) = %q, %v; want a plain ID", id, series, ok)
+		}
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	r, err := ParseRecurrence("freq=weekly;interval=2;byday=mo,fr,mo;count=10;until=2026.06.01", time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.String(), "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10;UNTIL=2026.06.01"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if r, err := ParseRecurrence("none", time.Local); r != nil || err != nil {
+		t.Errorf("none = %v, %v", r, err)
+	}
+	for _, bad := range []string{
+		"INTERVAL=2",                  // no FREQ
+		"FREQ=YEARLY",                 // unsupported
+		"FREQ=DAILY;INTERVAL=0",       // not positive
+		"FREQ=DAILY;COUNT=x",          // not a number
+		"FREQ=WEEKLY;BYDAY=XX",        // unknown day
+		"FREQ=MONTHLY;BYDAY=MO",       // BYDAY only for DAILY/WEEKLY
+		"FREQ=DAILY;UNTIL=2026.02.30", // no such date
+		"FREQ=DAILY;WKST=MO",          // unknown part
+		"FREQ",
+	} {
+		if _, err := ParseRecurrence(bad, time.Local); err == nil {
+			t.Errorf("ParseRecurrence(%q): want error", bad)
+		}
+	}
+}
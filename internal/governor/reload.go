@@ -0,0 +1,102 @@
+package governor
+
+import (
+	"fmt"
+	log "log/slog"
+	"os"
+	"time"
+)
+
+// scheduleWatchInterval is how often the schedule CSV is checked for changes.
+const scheduleWatchInterval = 2 * time.Second
+
+// slots returns the current weekly schedule. The slice is never modified
+// in place, only swapped, so callers may range over it without the lock.
+func (g *Governor) slots() []Slot {
+	g.scheduleMu.RLock()
+	defer g.scheduleMu.RUnlock()
+	return g.schedule
+}
+
+// loadSchedule reads and validates the schedule CSV. Every problem is
+// logged; in strict mode any problem fails the load, otherwise only the
+// rows with fatal problems are dropped. Weekday names are rewritten in the
+// locale's language.
+func (g *Governor) loadSchedule(path string) ([]Slot, error) {
+	slots, problems, err := LoadScheduleFromCSV(path, g.locale.location())
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range problems {
+		if g.strictSchedule {
+			log.Error("schedule problem", "path", path, "line", p.Line, "column", p.Column, "reason", p.Reason)
+		} else {
+			log.Warn("schedule problem", "path", path, "line", p.Line, "column", p.Column, "reason", p.Reason)
+		}
+	}
+	if g.strictSchedule && len(problems) > 0 {
+		return nil, &ScheduleError{Path: path, Problems: problems}
+	}
+	for i := range slots {
+		slots[i].Weekday = g.locale.WeekdayName(slots[i].Day)
+	}
+	return slots, nil
+}
+
+// ReloadSchedule re-reads the schedule CSV and swaps it in. If the file
+// can't be loaded or yields no slots, the current schedule stays in place.
+// Reloads (SIGHUP, file watch, NEW:RELOAD) run one at a time, so an older
+// parse can never replace a newer one.
+func (g *Governor) ReloadSchedule() (int, error) {
+	if g.schedulePath == "" {
+		return 0, fmt.Errorf("no schedule file configured")
+	}
+	g.reloadMu.Lock()
+	defer g.reloadMu.Unlock()
+	slots, err := g.loadSchedule(g.schedulePath)
+	if err != nil {
+		log.Error("schedule reload failed, keeping current schedule", "path", g.schedulePath, "err", err)
+		return 0, err
+	}
+	// A file with no usable rows is almost certainly mid-edit or broken.
+	if len(slots) == 0 && len(g.slots()) > 0 {
+		err := fmt.Errorf("schedule %s has no valid slots", g.schedulePath)
+		log.Error("schedule reload rejected, keeping current schedule", "path", g.schedulePath, "err", err)
+		return 0, err
+	}
+	g.scheduleMu.Lock()
+	g.schedule = slots
+	g.scheduleMu.Unlock()
+	g.notifyScheduleChanged()
+	log.Info("SCHEDULE RELOADED", "path", g.schedulePath, "slots", len(slots))
+	return len(slots), nil
+}
+
+// scheduleWatchLoop reloads the schedule when the CSV's size or mtime changes.
+func (g *Governor) scheduleWatchLoop() {
+	defer g.wg.Done()
+	stat := func() (time.Time, int64) {
+		fi, err := os.Stat(g.schedulePath)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return fi.ModTime(), fi.Size()
+	}
+	mtime, size := stat()
+	t := time.NewTicker(scheduleWatchInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-t.C:
+		}
+		m, sz := stat()
+		if sz < 0 || (m.Equal(mtime) && sz == size) {
+			continue
+		}
+		mtime, size = m, sz
+		log.Debug("schedule file changed", "path", g.schedulePath)
+		g.ReloadSchedule()
+	}
+}
@@ -0,0 +1,139 @@
+package governor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// reminderTick is how often the reminder loop looks for due reminders.
+const reminderTick = 30 * time.Second
+
+// errRemindStale aborts a watermark update for an event that was edited
+// while its reminders were being sent.
+var errRemindStale = errors.New("event changed during reminder send")
+
+// remindOffsets returns the offsets before At at which e should be reminded.
+func (g *Governor) remindOffsets(e Event) []time.Duration {
+	if e.RemindOff {
+		return nil
+	}
+	if len(e.Remind) > 0 {
+		return e.Remind
+	}
+	return g.remindDefault
+}
+
+func (g *Governor) reminderLoop() {
+	defer g.wg.Done()
+	t := time.NewTicker(reminderTick)
+	defer t.Stop()
+	for {
+		g.sendDueReminders(g.now())
+		select {
+		case <-g.stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// sendDueReminders pushes a reminder for every upcoming occurrence with a
+// reminder that fell due since the event's RemindedAt watermark. If several
+// offsets are due at once (e.g. after downtime) only the nearest is sent.
+// The watermark only covers sends that succeeded, and is persisted
+// with the event so a restart doesn't re-fire. If the event was edited or
+// trashed meanwhile the watermark is left alone: the sends were computed
+// from the old schedule and say nothing about the new one.
+func (g *Governor) sendDueReminders(now time.Time) {
+	for _, e := range g.liveEvents() {
+		offsets := g.remindOffsets(e)
+		if len(offsets) == 0 {
+			continue
+		}
+		var last time.Time
+		for _, occ := range e.Occurrences(now, now.Add(slices.Max(offsets))) {
+			if occ.State() != StatusOpen {
+				continue // done or cancelled: nothing to remind of
+			}
+			var fire time.Time
+			for _, off := range offsets {
+				f := occ.At.Add(-off)
+				if f.After(now) || (e.RemindedAt != nil && !f.After(*e.RemindedAt)) {
+					continue
+				}
+				if f.After(fire) {
+					fire = f
+				}
+			}
+			if fire.IsZero() {
+				continue
+			}
+			left := occ.At.Sub(now).Round(time.Minute)
+			if err := g.client.Send(g.remindTo, "NEW", "REMINDER", occ.WireString(), left.String()); err != nil {
+				log.Warn("reminder send failed", "to", g.remindTo, "id", occ.ID, "err", err)
+				break
+			}
+			log.Info("REMINDER", "to", g.remindTo, "id", occ.ID, "title", occ.Title, "left", left)
+			if fire.After(last) {
+				last = fire
+			}
+		}
+		if last.IsZero() {
+			continue
+		}
+		snap := e
+		_, err := g.updateLive(e.ID, func(e *Event) error {
+			if !sameReminders(*e, snap) {
+				return errRemindStale
+			}
+			e.RemindedAt = &last
+			return nil
+		})
+		switch {
+		case errors.Is(err, errRemindStale), errors.Is(err, ErrNoEvent):
+			log.Info("reminder watermark not advanced", "id", e.ID, "reason", err)
+		case err != nil:
+			log.Error("reminder watermark update failed", "id", e.ID, "err", err)
+		}
+	}
+}
+
+// sameReminders reports whether a and b would fire the same reminders:
+// same time, offsets, recurrence (exceptions included) and watermark.
+func sameReminders(a, b Event) bool {
+	if !a.At.Equal(b.At) || a.RemindOff != b.RemindOff || !slices.Equal(a.Remind, b.Remind) {
+		return false
+	}
+	if (a.RemindedAt == nil) != (b.RemindedAt == nil) ||
+		(a.RemindedAt != nil && !a.RemindedAt.Equal(*b.RemindedAt)) {
+		return false
+	}
+	ra, errA := json.Marshal(a.Recur)
+	rb, errB := json.Marshal(b.Recur)
+	return errA == nil && errB == nil && string(ra) == string(rb)
+}
+
+// ParseRemindOffsets parses a comma-separated list of durations (e.g. "24h,1h,15m").
+func ParseRemindOffsets(s string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("remind offset: %w", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("remind offset must be positive, got %s", part)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package governor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSameReminders(t *testing.T) {
+	at := time.Date(2026, time.March, 6, 10, 0, 0, 0, time.Local)
+	sent := at.Add(-time.Hour)
+	r, _ := ParseRecurrence("FREQ=WEEKLY", time.Local)
+	base := Event{ID: "ev1", At: at, Remind: []time.Duration{time.Hour}, Recur: r, RemindedAt: &sent}
+
+	if !sameReminders(base, base.clone()) {
+		t.Fatal("a clone differs from its original")
+	}
+	later := sent.Add(time.Minute)
+	moved := at.AddDate(0, 0, 14)
+	for name, edit := range map[string]func(*Event){
+		"at":           func(e *Event) { e.At = e.At.Add(time.Hour) },
+		"offsets":      func(e *Event) { e.Remind = []time.Duration{time.Hour, 15 * time.Minute} },
+		"off":          func(e *Event) { e.RemindOff = true },
+		"watermark":    func(e *Event) { e.RemindedAt = &later },
+		"no watermark": func(e *Event) { e.RemindedAt = nil },
+		"rule":         func(e *Event) { e.Recur.Interval = 2 },
+		"no rule":      func(e *Event) { e.Recur = nil },
+		"exception": func(e *Event) {
+			e.Recur.setException(RecurrenceException{On: time.Date(2026, time.March, 13, 0, 0, 0, 0, time.Local), At: &moved})
+		},
+	} {
+		e := base.clone()
+		edit(&e)
+		if sameReminders(base, e) {
+			t.Errorf("%s: edit not noticed", name)
+		}
+	}
+	e := base.clone()
+	e.Title, e.Notes = "Renamed", "unrelated"
+	if !sameReminders(base, e) {
+		t.Error("title edit counted as a reminder change")
+	}
+}
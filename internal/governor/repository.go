@@ -0,0 +1,64 @@
+package governor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EventRepository stores events. Implementations must be safe for
+// concurrent use; events returned are copies and change only through
+// Update. Unknown IDs make Update return ErrNoEvent and Delete return false.
+type EventRepository interface {
+	// Add stores e under a fresh ID (e.ID is ignored) and returns the ID.
+	Add(e Event) (string, error)
+	Get(id string) (Event, bool)
+	List() []Event
+	// Update applies fn to a copy of the event and stores the result; if
+	// fn or the write fails, the stored event is left unchanged.
+	Update(id string, fn func(*Event) error) (Event, error)
+	Delete(id string) bool
+	// DeleteIf deletes the event only if match holds for it, checked and
+	// deleted in one step, and returns the event as it was deleted.
+	DeleteIf(id string, match func(Event) bool) (Event, bool)
+	// Query returns the events for which match is true.
+	Query(match func(Event) bool) []Event
+	// Close flushes pending writes and releases the storage.
+	Close() error
+}
+
+// ErrNoEvent is returned by EventRepository.Update for an unknown ID.
+var ErrNoEvent = errors.New("no such event")
+
+// Storage backends for OpenEventRepository.
+const (
+	StoreJSON   = "json"   // JSON snapshot plus journal (default)
+	StoreMemory = "memory" // nothing persisted
+	StoreBolt   = "bolt"   // embedded bbolt key-value database
+)
+
+// NewJSONEventRepository keeps events in a JSON file at path with an
+// append-only journal beside it (see eventStore).
+func NewJSONEventRepository(path string) (EventRepository, error) {
+	return newEventStore(path)
+}
+
+// NewMemoryEventRepository keeps events in memory only; for tests and
+// throwaway instances.
+func NewMemoryEventRepository() EventRepository {
+	s, _ := newEventStore("")
+	return s
+}
+
+// OpenEventRepository opens the backend named kind (StoreJSON, StoreMemory,
+// StoreBolt) at path. path is ignored for StoreMemory.
+func OpenEventRepository(kind, path string) (EventRepository, error) {
+	switch kind {
+	case StoreJSON, "":
+		return NewJSONEventRepository(path)
+	case StoreMemory:
+		return NewMemoryEventRepository(), nil
+	case StoreBolt:
+		return NewBoltEventRepository(path)
+	}
+	return nil, fmt.Errorf("unknown event store %q (want %s, %s or %s)", kind, StoreJSON, StoreMemory, StoreBolt)
+}
@@ -0,0 +1,119 @@
+package governor
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// repoBackends opens each EventRepository backend in dir. Persistent ones
+// must find their events again when reopened from the same dir.
+var repoBackends = []struct {
+	name       string
+	open       func(t *testing.T, dir string) EventRepository
+	persistent bool
+}{
+	{StoreJSON, func(t *testing.T, dir string) EventRepository {
+		r, err := NewJSONEventRepository(filepath.Join(dir, "events.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}, true},
+	{StoreMemory, func(*testing.T, string) EventRepository { return NewMemoryEventRepository() }, false},
+	{StoreBolt, func(t *testing.T, dir string) EventRepository {
+		r, err := NewBoltEventRepository(filepath.Join(dir, "events.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}, true},
+}
+
+func TestEventRepositoryContract(t *testing.T) {
+	at := time.Date(2026, time.March, 6, 23, 59, 0, 0, time.Local)
+	for _, b := range repoBackends {
+		t.Run(b.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r := b.open(t, dir)
+
+			id1, err := r.Add(Event{ID: "ignored", Title: "Lab report", At: at, Tags: []string{"lab"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			id2, err := r.Add(Event{Title: "Exam", At: at.AddDate(0, 0, 7), Priority: PriorityHigh})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id1 == "" || id1 == "ignored" || id1 == id2 {
+				t.Fatalf("Add returned IDs %q, %q", id1, id2)
+			}
+
+			e, ok := r.Get(id1)
+			if !ok || e.ID != id1 || e.Title != "Lab report" || !e.At.Equal(at) || !slices.Equal(e.Tags, []string{"lab"}) {
+				t.Fatalf("Get(%s) = %+v, %v", id1, e, ok)
+			}
+			if _, ok := r.Get("ev999"); ok {
+				t.Fatal("Get of a missing ID succeeded")
+			}
+
+			got, err := r.Update(id1, func(e *Event) error { e.Title = "Lab report 2"; return nil })
+			if err != nil || got.Title != "Lab report 2" || got.ID != id1 {
+				t.Fatalf("Update = %+v, %v", got, err)
+			}
+			if e, _ := r.Get(id1); e.Title != "Lab report 2" {
+				t.Fatalf("after Update, Get = %+v", e)
+			}
+			failed := errors.New("rejected")
+			if _, err := r.Update(id1, func(e *Event) error { e.Title = "lost"; return failed }); !errors.Is(err, failed) {
+				t.Fatalf("failing Update err = %v, want %v", err, failed)
+			}
+			if e, _ := r.Get(id1); e.Title != "Lab report 2" {
+				t.Fatalf("failing Update changed the event: %+v", e)
+			}
+			if _, err := r.Update("ev999", func(*Event) error { return nil }); !errors.Is(err, ErrNoEvent) {
+				t.Fatalf("Update of a missing ID err = %v, want ErrNoEvent", err)
+			}
+
+			high := r.Query(func(e Event) bool { return e.Priority == PriorityHigh })
+			if len(high) != 1 || high[0].ID != id2 {
+				t.Fatalf("Query = %+v, want only %s", high, id2)
+			}
+			if n := len(r.List()); n != 2 {
+				t.Fatalf("List has %d events, want 2", n)
+			}
+
+			if _, ok := r.DeleteIf(id2, func(e Event) bool { return e.Deleted() }); ok {
+				t.Fatal("DeleteIf deleted although match was false")
+			}
+			if e, ok := r.DeleteIf(id2, func(Event) bool { return true }); !ok || e.Title != "Exam" {
+				t.Fatalf("DeleteIf = %+v, %v", e, ok)
+			}
+			if r.Delete(id2) {
+				t.Fatal("Delete of a deleted ID succeeded")
+			}
+			if _, ok := r.Get(id2); ok {
+				t.Fatal("deleted event still found")
+			}
+
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !b.persistent {
+				return
+			}
+			r = b.open(t, dir)
+			defer r.Close()
+			all := r.List()
+			if len(all) != 1 || all[0].ID != id1 || all[0].Title != "Lab report 2" || !all[0].At.Equal(at) {
+				t.Fatalf("after reopen, List = %+v", all)
+			}
+			id3, err := r.Add(Event{Title: "New", At: at})
+			if err != nil || id3 == id1 || id3 == id2 {
+				t.Fatalf("Add after reopen = %q, %v; IDs must not be reused", id3, err)
+			}
+		})
+	}
+}
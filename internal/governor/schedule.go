@@ -3,72 +3,278 @@ package governor
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
+
+	"governor/pkg/proto"
 )
 
 type Slot struct {
-	Weekday  string // Mon, Tue, ...
+	Weekday  string // Mon, Tue, ... (Пн, Вт, ... with a Russian locale)
 	Start    string // 10:45
 	End      string // 12:10
 	Title    string
 	Location string
 	Tags     string
+
+	// Columns governor doesn't know (teacher, group, link, ...) by lowercased
+	// header name. Empty cells are omitted.
+	Attrs map[string]string
+
+	// Optional columns: which teaching weeks, and the dates the row is valid
+	// for (zero = unbounded).
+	Weeks      WeekSet
+	ValidFrom  time.Time
+	ValidUntil time.Time
+
+	// Parsed at load.
+	Day     time.Weekday
+	StartAt time.Duration // Start as offset from midnight
+	EndAt   time.Duration // End as offset from midnight
+
+	line int // CSV line, for diagnostics
 }
 
-const slotSep = "|"
+// ActiveOn reports whether the slot runs on day, which is in teaching week
+// number week. The weekday is not checked.
+func (s Slot) ActiveOn(day time.Time, week int) bool {
+	day = startOfDay(day)
+	if !s.ValidFrom.IsZero() && day.Before(s.ValidFrom) {
+		return false
+	}
+	if !s.ValidUntil.IsZero() && day.After(s.ValidUntil) {
+		return false
+	}
+	return s.Weeks.Has(week)
+}
+
+// On returns the slot's start and end on the given day.
+func (s Slot) On(day time.Time) (start, end time.Time) {
+	d := startOfDay(day)
+	return d.Add(s.StartAt), d.Add(s.EndAt)
+}
 
-func noColon(s string) string { return strings.ReplaceAll(s, ":", ".") }
+// wireClock writes a CSV clock time (10:45) in the dotted wire form (10.45).
+func wireClock(s string) string { return strings.ReplaceAll(s, ":", ".") }
 
-// Format: Weekday|Start|End|Title|Location|Tags
+// Format: Weekday|Start|End|Title|Location|Tags|Weeks[|<attr>=<value>...]
+// (fields escaped via proto.JoinFields; attributes sorted by name)
 func (s Slot) WireString() string {
-	return strings.Join([]string{
-		noColon(s.Weekday), noColon(s.Start), noColon(s.End),
-		noColon(s.Title), noColon(s.Location), noColon(s.Tags),
-	}, slotSep)
+	fields := []string{
+		s.Weekday, wireClock(s.Start), wireClock(s.End),
+		s.Title, s.Location, s.Tags, s.Weeks.String(),
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.Attrs)) {
+		fields = append(fields, k+"="+s.Attrs[k])
+	}
+	return proto.JoinFields(fields...)
 }
 
-func LoadScheduleFromCSV(path string) ([]Slot, error) {
+// Field returns a column value by header name: a known column or an attribute.
+func (s Slot) Field(name string) string {
+	switch strings.ToLower(name) {
+	case "weekday":
+		return s.Weekday
+	case "start":
+		return s.Start
+	case "end":
+		return s.End
+	case "title":
+		return s.Title
+	case "location":
+		return s.Location
+	case "tags":
+		return s.Tags
+	case "weeks":
+		return s.Weeks.String()
+	}
+	return s.Attrs[strings.ToLower(name)]
+}
+
+// parseClock parses a CSV clock time (10:45 or 10.45) as an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(strings.ReplaceAll(strings.TrimSpace(s), ".", ":"), "%d:%d", &h, &m); err != nil {
+		return 0, fmt.Errorf("clock %q: %w", s, err)
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("clock %q out of range", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// parseCSVDate accepts YYYY.MM.DD or YYYY-MM-DD, as midnight in loc.
+func parseCSVDate(s string, loc *time.Location) (time.Time, error) {
+	return ParseDate(strings.ReplaceAll(strings.TrimSpace(s), "-", "."), loc)
+}
+
+// scheduleColumns are the CSV columns governor understands. Columns are
+// matched by header name in any order; other columns become Slot.Attrs.
+var scheduleColumns = []string{"weekday", "start", "end", "title", "location", "tags", "weeks", "valid_from", "valid_until"}
+
+// requiredScheduleColumns must be present in the header.
+var requiredScheduleColumns = []string{"weekday", "start", "end", "title"}
+
+// LoadScheduleFromCSV reads the schedule and checks every row. It returns
+// the usable slots plus every problem found; rows with a fatal problem
+// (bad weekday, time, weeks or date, end not after start, missing title or
+// fields) are left out, while overlaps and header issues only warn. A header
+// without a required column yields no slots.
+// The error is reserved for files that can't be read as CSV at all.
+// valid_from and valid_until are dates in loc.
+func LoadScheduleFromCSV(path string, loc *time.Location) ([]Slot, []ScheduleProblem, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("open schedule: %w", err)
+		return nil, nil, fmt.Errorf("open schedule: %w", err)
 	}
 	defer f.Close()
 
 	r := csv.NewReader(f)
 	r.FieldsPerRecord = -1 // allow variable number of fields
-	rows, err := r.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("read csv: %w", err)
+
+	var problems []ScheduleProblem
+	report := func(line int, col, format string, args ...any) {
+		problems = append(problems, ScheduleProblem{Line: line, Column: col, Reason: fmt.Sprintf(format, args...)})
 	}
 
-	if len(rows) < 2 {
-		return nil, nil
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv: %w", err)
+	}
+	// column name -> index; names not in scheduleColumns are attributes
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			report(1, fmt.Sprintf("#%d", i+1), "empty column name, column ignored")
+			continue
+		}
+		if _, dup := cols[name]; dup {
+			report(1, name, "duplicate column, later one ignored")
+			continue
+		}
+		cols[name] = i
+	}
+	missingColumn := false
+	for _, name := range requiredScheduleColumns {
+		if _, ok := cols[name]; !ok {
+			report(1, name, "missing required column")
+			missingColumn = true
+		}
+	}
+	if missingColumn {
+		return nil, problems, nil
 	}
 
-	// skip header
 	var slots []Slot
-	for _, row := range rows[1:] {
-		if len(row) < 6 {
-			continue
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read csv: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
 		}
-		weekday := strings.TrimSpace(row[0])
-		start := strings.TrimSpace(row[1])
-		end := strings.TrimSpace(row[2])
-		title := strings.TrimSpace(row[3])
-		location := strings.TrimSpace(row[4])
-		tags := strings.TrimSpace(row[5])
-		if title == "" {
+		var missing []string
+		for _, name := range requiredScheduleColumns {
+			if cols[name] >= len(row) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			report(line, "", "row too short, missing %s", strings.Join(missing, ", "))
 			continue
 		}
-		slots = append(slots, Slot{
-			Weekday:  weekday,
-			Start:    start,
-			End:      end,
-			Title:    title,
-			Location: location,
-			Tags:     tags,
-		})
+		slot := Slot{
+			Weekday:  field("weekday"),
+			Start:    field("start"),
+			End:      field("end"),
+			Title:    field("title"),
+			Location: field("location"),
+			Tags:     field("tags"),
+			line:     line,
+		}
+		for name, i := range cols {
+			if slices.Contains(scheduleColumns, name) || i >= len(row) {
+				continue
+			}
+			if v := strings.TrimSpace(row[i]); v != "" {
+				if slot.Attrs == nil {
+					slot.Attrs = make(map[string]string)
+				}
+				slot.Attrs[name] = v
+			}
+		}
+		ok := true
+		if slot.Title == "" {
+			report(line, "title", "empty title")
+			ok = false
+		}
+		day, dayOK := ParseWeekday(slot.Weekday)
+		if !dayOK {
+			report(line, "weekday", "unknown weekday %q", slot.Weekday)
+			ok = false
+		}
+		slot.Day = day
+		var err1, err2 error
+		if slot.StartAt, err1 = parseClock(slot.Start); err1 != nil {
+			report(line, "start", "unparsable time %q", slot.Start)
+			ok = false
+		}
+		if slot.EndAt, err2 = parseClock(slot.End); err2 != nil {
+			report(line, "end", "unparsable time %q", slot.End)
+			ok = false
+		}
+		if err1 == nil && err2 == nil && slot.EndAt <= slot.StartAt {
+			report(line, "end", "end %s is not after start %s", slot.End, slot.Start)
+			ok = false
+		}
+		// optional: weeks, valid_from, valid_until
+		if w, err := ParseWeekSet(field("weeks")); err != nil {
+			report(line, "weeks", "%v", err)
+			ok = false
+		} else {
+			slot.Weeks = w
+		}
+		for _, d := range []struct {
+			col string
+			dst *time.Time
+		}{{"valid_from", &slot.ValidFrom}, {"valid_until", &slot.ValidUntil}} {
+			if field(d.col) == "" {
+				continue
+			}
+			t, err := parseCSVDate(field(d.col), loc)
+			if err != nil {
+				report(line, d.col, "unparsable date %q", field(d.col))
+				ok = false
+				continue
+			}
+			*d.dst = t
+		}
+		if !slot.ValidFrom.IsZero() && !slot.ValidUntil.IsZero() && slot.ValidUntil.Before(slot.ValidFrom) {
+			report(line, "valid_until", "valid_until is before valid_from")
+			ok = false
+		}
+		if ok {
+			slots = append(slots, slot)
+		}
 	}
-	return slots, nil
+	problems = append(problems, findOverlaps(slots)...)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return slots, problems, nil
 }
@@ -0,0 +1,97 @@
+package governor
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// loadCSV writes content to a temp schedule file and loads it.
+func loadCSV(t *testing.T, content string) ([]Slot, []ScheduleProblem) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schedule.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	slots, problems, err := LoadScheduleFromCSV(path, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return slots, problems
+}
+
+func TestScheduleHeaderMapping(t *testing.T) {
+	slots, problems := loadCSV(t, ""+
+		"Title, teacher ,END,weekday,Start,location,group,weeks\n"+
+		"Math,Ivanov,10:30,Mon,09:00,R1,b|2,odd\n"+
+		"Physics,,12:30,Tue,11:00,R2,,\n")
+	if len(problems) != 0 {
+		t.Fatalf("problems: %v", problems)
+	}
+	if len(slots) != 2 {
+		t.Fatalf("got %d slots, want 2", len(slots))
+	}
+
+	math := slots[0]
+	if math.Weekday != "Mon" || math.Day != time.Monday || math.Start != "09:00" || math.End != "10:30" ||
+		math.Title != "Math" || math.Location != "R1" || math.Weeks.Parity != 1 {
+		t.Errorf("Math = %+v", math)
+	}
+	if math.StartAt != 9*time.Hour || math.EndAt != 10*time.Hour+30*time.Minute {
+		t.Errorf("Math runs %v-%v", math.StartAt, math.EndAt)
+	}
+	if want := map[string]string{"teacher": "Ivanov", "group": "b|2"}; !maps.Equal(math.Attrs, want) {
+		t.Errorf("Math attrs = %v, want %v", math.Attrs, want)
+	}
+	if got := math.Field("Teacher"); got != "Ivanov" {
+		t.Errorf(`Field("Teacher") = %q`, got)
+	}
+	// Attributes follow the fixed fields, sorted by name whatever the
+	// column order; field separators inside values are escaped.
+	if got, want := math.WireString(), "Mon|09.00|10.30|Math|R1||odd|group=b%7C2|teacher=Ivanov"; got != want {
+		t.Errorf("WireString = %q, want %q", got, want)
+	}
+
+	// Empty attribute cells are left out.
+	if slots[1].Attrs != nil {
+		t.Errorf("Physics attrs = %v, want none", slots[1].Attrs)
+	}
+	if got, want := slots[1].WireString(), "Tue|11.00|12.30|Physics|R2||"; got != want {
+		t.Errorf("WireString = %q, want %q", got, want)
+	}
+}
+
+func TestScheduleHeaderProblems(t *testing.T) {
+	slots, problems := loadCSV(t, ""+
+		"weekday,start,end,title,,Title,room\n"+
+		"Mon,09:00,10:30,Math,stray,Algebra,R1\n")
+	want := []ScheduleProblem{
+		{1, "#5", "empty column name, column ignored"},
+		{1, "title", "duplicate column, later one ignored"},
+	}
+	if !slices.Equal(problems, want) {
+		t.Errorf("problems = %v, want %v", problems, want)
+	}
+	if len(slots) != 1 {
+		t.Fatalf("got %d slots, want 1", len(slots))
+	}
+	if s := slots[0]; s.Title != "Math" || !maps.Equal(s.Attrs, map[string]string{"room": "R1"}) {
+		t.Errorf("slot = %+v; want the first title column and only room as an attribute", s)
+	}
+}
+
+func TestScheduleMissingColumn(t *testing.T) {
+	slots, problems := loadCSV(t, ""+
+		"weekday,start,title\n"+
+		"Mon,09:00,Math\n")
+	want := []ScheduleProblem{{1, "end", "missing required column"}}
+	if !slices.Equal(problems, want) {
+		t.Errorf("problems = %v, want %v", problems, want)
+	}
+	if len(slots) != 0 {
+		t.Errorf("got %d slots from a header without end", len(slots))
+	}
+}
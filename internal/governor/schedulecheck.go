@@ -0,0 +1,81 @@
+package governor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScheduleProblem is one issue found in the schedule CSV.
+type ScheduleProblem struct {
+	Line   int    // CSV line, 1 = header
+	Column string // column name; empty when the whole row is affected
+	Reason string
+}
+
+func (p ScheduleProblem) String() string {
+	if p.Column == "" {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Reason)
+	}
+	return fmt.Sprintf("line %d, %s: %s", p.Line, p.Column, p.Reason)
+}
+
+// ScheduleError is returned when a schedule fails strict validation.
+type ScheduleError struct {
+	Path     string
+	Problems []ScheduleProblem
+}
+
+func (e *ScheduleError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("schedule %s: %d problem(s): %s", e.Path, len(e.Problems), strings.Join(lines, "; "))
+}
+
+// maxWeek bounds week-set comparisons; no semester runs longer.
+const maxWeek = 60
+
+// intersects reports whether both sets select at least one common week.
+func (w WeekSet) intersects(o WeekSet) bool {
+	for n := 1; n <= maxWeek; n++ {
+		if w.Has(n) && o.Has(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// findOverlaps reports slots on the same weekday whose times overlap and
+// that can run in the same week and on the same dates. Each problem is
+// reported on the later row.
+func findOverlaps(slots []Slot) []ScheduleProblem {
+	var out []ScheduleProblem
+	for i := range slots {
+		for j := i + 1; j < len(slots); j++ {
+			a, b := slots[i], slots[j]
+			if a.Day != b.Day || a.StartAt >= b.EndAt || b.StartAt >= a.EndAt {
+				continue
+			}
+			if !a.Weeks.intersects(b.Weeks) || !datesIntersect(a, b) {
+				continue
+			}
+			out = append(out, ScheduleProblem{
+				Line:   b.line,
+				Reason: fmt.Sprintf("overlaps %q on line %d (%s %s-%s)", a.Title, a.line, a.Weekday, a.Start, a.End),
+			})
+		}
+	}
+	return out
+}
+
+// datesIntersect reports whether the valid_from/valid_until ranges of a and b overlap.
+func datesIntersect(a, b Slot) bool {
+	if !a.ValidUntil.IsZero() && !b.ValidFrom.IsZero() && a.ValidUntil.Before(b.ValidFrom) {
+		return false
+	}
+	if !b.ValidUntil.IsZero() && !a.ValidFrom.IsZero() && b.ValidUntil.Before(a.ValidFrom) {
+		return false
+	}
+	return true
+}
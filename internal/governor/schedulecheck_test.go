@@ -0,0 +1,71 @@
+package governor
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+const problemsCSV = "testdata/schedule_problems.csv"
+
+// wantProblems are the problems in problemsCSV, in report order.
+var wantProblems = []ScheduleProblem{
+	// Math (odd) and Physics (even) never meet; Chemistry in weeks 1-3 meets both.
+	{4, "", `overlaps "Math" on line 2 (Mon 09:00-10:30)`},
+	{4, "", `overlaps "Physics" on line 3 (Mon 10:00-11:30)`},
+	// The two History rows have disjoint dates; Art from mid-March meets both.
+	{7, "", `overlaps "History" on line 5 (Tue 09:00-10:30)`},
+	{7, "", `overlaps "History II" on line 6 (Tue 09:00-10:30)`},
+	{8, "end", "end 11:00 is not after start 12:00"},
+	{9, "weekday", `unknown weekday "Xyz"`},
+	{10, "start", `unparsable time "25:00"`},
+	{10, "end", `unparsable time "9.61"`},
+	{11, "weeks", `weeks: bad week "0"`},
+	{12, "valid_from", `unparsable date "2026.02.30"`},
+	{13, "valid_until", "valid_until is before valid_from"},
+	{14, "", "row too short, missing end, title"},
+	{15, "title", "empty title"},
+}
+
+func TestLoadScheduleProblems(t *testing.T) {
+	slots, problems, err := LoadScheduleFromCSV(problemsCSV, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(problems, wantProblems) {
+		t.Errorf("problems:\n%v\nwant:\n%v", problems, wantProblems)
+	}
+	// Overlaps only warn; every other problem drops its row.
+	var titles []string
+	for _, s := range slots {
+		titles = append(titles, s.Title)
+	}
+	if want := []string{"Math", "Physics", "Chemistry", "History", "History II", "Art"}; !slices.Equal(titles, want) {
+		t.Errorf("slots %q, want %q", titles, want)
+	}
+}
+
+func TestStrictScheduleFailsNew(t *testing.T) {
+	client := proto.New("GOVERNOR", "ws://localhost:0")
+
+	_, err := New(client, problemsCSV, "", WithStrictSchedule(true))
+	var serr *ScheduleError
+	if !errors.As(err, &serr) {
+		t.Fatalf("strict New err = %v, want a *ScheduleError", err)
+	}
+	if serr.Path != problemsCSV || !slices.Equal(serr.Problems, wantProblems) {
+		t.Errorf("ScheduleError = %s %v", serr.Path, serr.Problems)
+	}
+
+	g, err := New(client, problemsCSV, "")
+	if err != nil {
+		t.Fatalf("lenient New: %v", err)
+	}
+	defer g.Shutdown()
+	if n := len(g.slots()); n != 6 {
+		t.Errorf("lenient New loaded %d slots, want 6", n)
+	}
+}
@@ -0,0 +1,116 @@
+package governor
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// slotsOn returns the classes that take place on day, ordered by start
+// time: the weekly rows for that date with schedule exceptions applied.
+func (g *Governor) slotsOn(day time.Time) []Slot {
+	out := g.applyExceptions(day, g.weeklySlotsOn(day))
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartAt < out[j].StartAt })
+	return out
+}
+
+// weeklySlotsOn returns the day's weekday rows that run in its teaching
+// week, within the semester, ignoring exceptions.
+func (g *Governor) weeklySlotsOn(day time.Time) []Slot {
+	if !g.semester.Contains(day) {
+		return nil
+	}
+	week := g.semester.Week(day, g.locale.WeekStart)
+	var out []Slot
+	for _, s := range g.slots() {
+		if s.Day == day.Weekday() && s.ActiveOn(day, week) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// currentSlot returns the matching slot in progress at now.
+func (g *Governor) currentSlot(now time.Time, match func(Slot) bool) (Slot, time.Time, bool) {
+	for _, s := range g.slotsOn(now) {
+		start, end := s.On(now)
+		if !now.Before(start) && now.Before(end) && match(s) {
+			return s, end, true
+		}
+	}
+	return Slot{}, time.Time{}, false
+}
+
+// nextSlot returns the first matching slot starting after now, looking up
+// to two weeks ahead (odd/even rows repeat fortnightly).
+func (g *Governor) nextSlot(now time.Time, match func(Slot) bool) (Slot, time.Time, bool) {
+	for d := 0; d <= 14; d++ {
+		day := startOfDay(now).AddDate(0, 0, d)
+		for _, s := range g.slotsOn(day) {
+			if start, _ := s.On(day); start.After(now) && match(s) {
+				return s, start, true
+			}
+		}
+	}
+	return Slot{}, time.Time{}, false
+}
+
+// scheduleDay resolves a GET:SCHEDULE day argument (TODAY, TOMORROW, their
+// Russian СЕГОДНЯ, ЗАВТРА, or a weekday name) to a date: today/tomorrow, or that weekday's next date
+// (today included). GET:SCHEDULE:DATE:<date> is handled by the caller.
+func scheduleDay(arg string, now time.Time) (time.Time, bool) {
+	today := startOfDay(now)
+	switch strings.ToUpper(strings.TrimSpace(arg)) {
+	case "TODAY", "СЕГОДНЯ":
+		return today, true
+	case "TOMORROW", "ЗАВТРА":
+		return today.AddDate(0, 0, 1), true
+	}
+	wd, ok := ParseWeekday(arg)
+	if !ok {
+		return time.Time{}, false
+	}
+	return today.AddDate(0, 0, (int(wd)-int(now.Weekday())+7)%7), true
+}
+
+// slotFilter builds a predicate from <column>=<value> args, ANDed. Any
+// column or attribute can be used; values compare case-insensitively,
+// tags match any one of a slot's ;-separated tags and weekday takes any
+// name ParseWeekday knows.
+func slotFilter(args []string) (func(Slot) bool, error) {
+	type cond struct{ name, value string }
+	var conds []cond
+	for _, a := range args {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return nil, replyErr{"FILTER", a}
+		}
+		conds = append(conds, cond{strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)})
+	}
+	return func(s Slot) bool {
+		for _, c := range conds {
+			v := s.Field(c.name)
+			if c.name == "tags" {
+				if !slices.ContainsFunc(strings.Split(v, ";"), func(t string) bool {
+					return strings.EqualFold(strings.TrimSpace(t), c.value)
+				}) {
+					return false
+				}
+				continue
+			}
+			if c.name == "weekday" {
+				if wd, ok := ParseWeekday(c.value); ok {
+					if s.Day != wd {
+						return false
+					}
+					continue
+				}
+			}
+			if !strings.EqualFold(v, c.value) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,181 @@
+package governor
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"governor/pkg/proto"
+)
+
+// searchMaxResults caps a GET:SEARCH reply.
+const searchMaxResults = 50
+
+// searchHorizon is how far ahead classes and occurrences are looked up to
+// date a result.
+const searchHorizon = 14 * 24 * time.Hour
+
+// Field weights: a hit in the title counts most, then tags, location, and
+// finally notes and slot attributes (teacher, group, ...).
+const (
+	weightTitle    = 6
+	weightTags     = 4
+	weightLocation = 3
+	weightNotes    = 2
+)
+
+// searchResult is one hit: an event or a class, its relevance score and
+// the date used to rank equally relevant hits (nearest first).
+type searchResult struct {
+	event *Event
+	slot  *Slot
+	when  time.Time // next occurrence; zero if unknown
+	score int
+}
+
+// Format: EVENT|<event fields...>  or  SLOT|<date>|<slot fields...>
+// (date = the class's next date, YYYY.MM.DD, empty if none within two weeks)
+func (r searchResult) WireString() string {
+	if r.event != nil {
+		return "EVENT" + proto.FieldSep + r.event.WireString()
+	}
+	date := ""
+	if !r.when.IsZero() {
+		date = r.when.Format("2006.01.02")
+	}
+	return proto.JoinFields("SLOT", date) + proto.FieldSep + r.slot.WireString()
+}
+
+// normalizeText lowercases s and folds ё into е, so "Ёлка" finds "елка".
+func normalizeText(s string) string {
+	return strings.NewReplacer("ё", "е").Replace(strings.ToLower(s))
+}
+
+// searchWords splits normalized text into words of letters and digits.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(normalizeText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// scoreTerm rates how well term matches text: a whole word beats a word
+// prefix, which beats a substring.
+func scoreTerm(term, text string) int {
+	best := 0
+	for _, w := range searchWords(text) {
+		switch {
+		case w == term:
+			return 3
+		case strings.HasPrefix(w, term):
+			best = max(best, 2)
+		case strings.Contains(w, term):
+			best = max(best, 1)
+		}
+	}
+	return best
+}
+
+// searchField is a piece of searchable text and its weight.
+type searchField struct {
+	text   string
+	weight int
+}
+
+// scoreFields returns the relevance of fields for terms, or 0 unless every
+// term matches somewhere.
+func scoreFields(terms []string, fields []searchField) int {
+	total := 0
+	for _, t := range terms {
+		best := 0
+		for _, f := range fields {
+			best = max(best, scoreTerm(t, f.text)*f.weight)
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+// search finds live events and schedule slots matching every word of
+// query, most relevant first, nearest in time among equals.
+func (g *Governor) search(query string, now time.Time) []searchResult {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	var out []searchResult
+
+	for _, e := range g.liveEvents() {
+		score := scoreFields(terms, []searchField{
+			{e.Title, weightTitle},
+			{strings.Join(e.Tags, " "), weightTags},
+			{e.Location, weightLocation},
+			{e.Notes, weightNotes},
+		})
+		if score == 0 {
+			continue
+		}
+		when := e.At
+		if next := e.Occurrences(now, now.Add(searchHorizon)); len(next) > 0 {
+			when = next[0].At
+		}
+		out = append(out, searchResult{event: &e, when: when, score: score})
+	}
+
+	// Each schedule row once, dated by its next class (exceptions applied).
+	seen := make(map[int]bool)
+	addSlot := func(s Slot, when time.Time) {
+		if seen[s.line] {
+			return
+		}
+		seen[s.line] = true
+		fields := []searchField{
+			{s.Title, weightTitle},
+			{s.Tags, weightTags},
+			{s.Location, weightLocation},
+		}
+		for _, k := range slices.Sorted(maps.Keys(s.Attrs)) {
+			fields = append(fields, searchField{s.Attrs[k], weightNotes})
+		}
+		if score := scoreFields(terms, fields); score > 0 {
+			out = append(out, searchResult{slot: &s, when: when, score: score})
+		}
+	}
+	for d := 0; d < int(searchHorizon/(24*time.Hour)); d++ {
+		day := startOfDay(now).AddDate(0, 0, d)
+		for _, s := range g.slotsOn(day) {
+			if start, _ := s.On(day); start.After(now) {
+				addSlot(s, start)
+			}
+		}
+	}
+	for _, s := range g.slots() {
+		addSlot(s, time.Time{})
+	}
+
+	distance := func(r searchResult) time.Duration {
+		if r.when.IsZero() {
+			return 1<<63 - 1
+		}
+		d := r.when.Sub(now)
+		if d < 0 {
+			d = -d
+		}
+		return d
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].score != out[j].score {
+			return out[i].score > out[j].score
+		}
+		return distance(out[i]) < distance(out[j])
+	})
+	if len(out) > searchMaxResults {
+		out = out[:searchMaxResults]
+	}
+	return out
+}
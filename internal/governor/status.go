@@ -0,0 +1,118 @@
+package governor
+
+import (
+	"errors"
+	log "log/slog"
+	"strings"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// Event statuses. The zero value of Event.Status means StatusOpen.
+const (
+	StatusOpen      = "open"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+)
+
+// statusVerbs maps the status verbs to the status they set.
+var statusVerbs = map[string]string{
+	"DONE":   StatusDone,
+	"CANCEL": StatusCancelled,
+	"REOPEN": StatusOpen,
+}
+
+// State returns the event's status, StatusOpen if unset.
+func (e Event) State() string {
+	if e.Status == "" {
+		return StatusOpen
+	}
+	return e.Status
+}
+
+// setStatus moves e to status, stamping CompletedAt when it leaves open.
+// Setting the status it already has keeps the original timestamp.
+func setStatus(status *string, completedAt **time.Time, to string, now time.Time) {
+	if to == StatusOpen {
+		*status, *completedAt = "", nil
+		return
+	}
+	if *status == to && *completedAt != nil {
+		return
+	}
+	*status, *completedAt = to, &now
+}
+
+// statusMatcher returns a predicate for a status= value: open, done,
+// cancelled, overdue (judged at now) or all. ok is false for anything else.
+func statusMatcher(want string, now time.Time) (match func(Event) bool, ok bool) {
+	switch want {
+	case "all", "":
+		return func(Event) bool { return true }, true
+	case StatusOverdue:
+		return func(e Event) bool { return e.Overdue(now) }, true
+	case StatusOpen, StatusDone, StatusCancelled:
+		return func(e Event) bool { return e.State() == want }, true
+	}
+	return nil, false
+}
+
+// cmdStatus handles DONE, CANCEL and REOPEN on EVENT. An occurrence ID
+// (series@date) changes only that occurrence.
+func (g *Governor) cmdStatus(req *proto.Request) {
+	msg := req.Msg
+	if msg.Noun != "EVENT" {
+		log.Warn("UNKNOWN NOUN", "noun", msg.Noun, "from", msg.From)
+		g.reply(req, "ERR", "NOUN")
+		return
+	}
+	if len(msg.Args) < 1 {
+		g.reply(req, "ERR", "ARGC")
+		return
+	}
+	to := statusVerbs[msg.Verb]
+	id := strings.TrimSpace(msg.Args[0])
+	now := g.now()
+
+	var before, after Event
+	var err error
+	if series, day, ok := splitInstanceID(id, g.locale.location()); ok {
+		_, err = g.updateLive(series, func(e *Event) error {
+			inst, ok := e.Occurrence(day)
+			if !ok {
+				return ErrNoEvent
+			}
+			before = inst.clone()
+			ex, _ := e.Recur.exception(day)
+			ex.On = day
+			setStatus(&ex.Status, &ex.CompletedAt, to, now)
+			if to == StatusOpen && e.Status != "" {
+				ex.Status = StatusOpen // reopen one occurrence of a closed series
+			}
+			e.Recur.setException(ex)
+			after = inst
+			after.Status, after.CompletedAt = ex.Status, ex.CompletedAt
+			return nil
+		})
+	} else {
+		after, err = g.updateLive(id, func(e *Event) error {
+			before = e.clone()
+			setStatus(&e.Status, &e.CompletedAt, to, now)
+			return nil
+		})
+	}
+	if errors.Is(err, ErrNoEvent) {
+		log.Debug(msg.Verb+" EVENT NOT FOUND", "id", id, "from", msg.From)
+		g.reply(req, "ERR", "NAC")
+		return
+	}
+	if err != nil {
+		log.Error(msg.Verb+" EVENT failed", "id", id, "from", msg.From, "err", err)
+		g.reply(req, "ERR", msg.Verb, err.Error())
+		return
+	}
+	g.record(msg.From, msg.Verb, id, &before, &after)
+	log.Info(msg.Verb+" EVENT", "id", id, "status", after.State(), "from", msg.From)
+	g.reply(req, "OK", "EVENT", after.WireString())
+}
@@ -0,0 +1,54 @@
+package governor
+
+import (
+	"slices"
+	"strings"
+)
+
+// tagSep separates tags in the tags field, as in the schedule CSV.
+const tagSep = ";"
+
+// Event priorities. The zero value of Event.Priority means PriorityNormal.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// Level returns the event's priority, PriorityNormal if unset.
+func (e Event) Level() string {
+	if e.Priority == "" {
+		return PriorityNormal
+	}
+	return e.Priority
+}
+
+// HasTag reports whether e carries tag, ignoring case.
+func (e Event) HasTag(tag string) bool {
+	return slices.ContainsFunc(e.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+// parseTags splits a ;-separated tag list, dropping blanks and repeats.
+func parseTags(s string) []string {
+	var out []string
+	for _, t := range strings.Split(s, tagSep) {
+		t = strings.TrimSpace(t)
+		if t == "" || slices.ContainsFunc(out, func(o string) bool { return strings.EqualFold(o, t) }) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// parsePriority accepts low, normal or high in any case; empty means
+// normal. The result is what Event.Priority stores ("" for normal).
+func parsePriority(s string) (string, bool) {
+	switch p := strings.ToLower(strings.TrimSpace(s)); p {
+	case PriorityLow, PriorityHigh:
+		return p, true
+	case PriorityNormal, "":
+		return "", true
+	}
+	return "", false
+}
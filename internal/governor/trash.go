@@ -0,0 +1,110 @@
+package governor
+
+import (
+	log "log/slog"
+	"sort"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// DefaultTrashRetention is how long deleted events stay restorable.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// trashPurgeTick is how often expired trash is purged.
+const trashPurgeTick = time.Hour
+
+// Deleted reports whether the event is in the trash.
+func (e Event) Deleted() bool { return e.DeletedAt != nil }
+
+// TrashWireString is the event record followed by the deletion time.
+// Format: id|title|at|location|notes|visible_from|recur|status|completed_at|tags|priority|deleted_at
+// (the WireString fields, then deleted_at like at)
+func (e Event) TrashWireString() string {
+	var deletedAt string
+	if e.DeletedAt != nil {
+		deletedAt = e.DeletedAt.Format(eventWireFmt)
+	}
+	return e.WireString() + proto.FieldSep + deletedAt
+}
+
+// WithTrashRetention sets how long deleted events can be restored before
+// they are purged for good. 0 keeps them forever.
+func WithTrashRetention(d time.Duration) Option {
+	return func(g *Governor) { g.trashRetention = d }
+}
+
+// liveEvents returns all events that are not in the trash.
+func (g *Governor) liveEvents() []Event {
+	return g.events.Query(func(e Event) bool { return !e.Deleted() })
+}
+
+// updateLive is events.Update that treats trashed events as missing.
+func (g *Governor) updateLive(id string, fn func(*Event) error) (Event, error) {
+	return g.events.Update(id, func(e *Event) error {
+		if e.Deleted() {
+			return ErrNoEvent
+		}
+		return fn(e)
+	})
+}
+
+// trashEvent moves a live event to the trash and returns it as it was.
+func (g *Governor) trashEvent(id string) (Event, error) {
+	var before Event
+	_, err := g.updateLive(id, func(e *Event) error {
+		before = e.clone()
+		now := g.now()
+		e.DeletedAt = &now
+		return nil
+	})
+	return before, err
+}
+
+// restoreEvent takes an event out of the trash under its original ID.
+func (g *Governor) restoreEvent(id string) (Event, error) {
+	return g.events.Update(id, func(e *Event) error {
+		if !e.Deleted() {
+			return ErrNoEvent
+		}
+		e.DeletedAt = nil
+		return nil
+	})
+}
+
+// trash returns the events in the trash, most recently deleted first.
+func (g *Governor) trash() []Event {
+	out := g.events.Query(Event.Deleted)
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(*out[j].DeletedAt) })
+	return out
+}
+
+func (g *Governor) trashLoop() {
+	defer g.wg.Done()
+	t := time.NewTicker(trashPurgeTick)
+	defer t.Stop()
+	for {
+		g.purgeTrash(g.now())
+		select {
+		case <-g.stop:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// purgeTrash deletes trashed events older than the retention period.
+// Each one is re-checked as it is deleted, so an event restored meanwhile
+// stays.
+func (g *Governor) purgeTrash(now time.Time) {
+	cutoff := now.Add(-g.trashRetention)
+	expired := func(e Event) bool { return e.Deleted() && e.DeletedAt.Before(cutoff) }
+	for _, e := range g.events.Query(expired) {
+		e, ok := g.events.DeleteIf(e.ID, expired)
+		if !ok {
+			continue
+		}
+		g.record(g.client.NodeID(), "PURGE", e.ID, &e, nil)
+		log.Info("PURGED EVENT", "id", e.ID, "title", e.Title, "deleted_at", e.DeletedAt.Format("2006-01-02 15:04"))
+	}
+}
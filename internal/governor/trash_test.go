@@ -0,0 +1,51 @@
+package governor
+
+import (
+	"testing"
+	"time"
+
+	"governor/pkg/proto"
+)
+
+// restoringRepo restores an event right after Query lists it, as a
+// NEW:RESTORE racing with the purge would.
+type restoringRepo struct {
+	EventRepository
+	g  *Governor
+	id string
+}
+
+func (r restoringRepo) Query(match func(Event) bool) []Event {
+	out := r.EventRepository.Query(match)
+	if _, err := r.g.restoreEvent(r.id); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func TestPurgeTrashSkipsRestored(t *testing.T) {
+	history, _ := newHistoryLog("")
+	g := &Governor{
+		client:         proto.New("GOVERNOR", "ws://localhost:0"),
+		history:        history,
+		trashRetention: time.Hour,
+	}
+	repo := NewMemoryEventRepository()
+	g.events = repo
+	deletedAt := time.Now().Add(-2 * time.Hour)
+	keep, _ := repo.Add(Event{Title: "restored", At: time.Now(), DeletedAt: &deletedAt})
+	purge, _ := repo.Add(Event{Title: "expired", At: time.Now(), DeletedAt: &deletedAt})
+	g.events = restoringRepo{repo, g, keep}
+
+	g.purgeTrash(time.Now())
+
+	if e, ok := repo.Get(keep); !ok || e.Deleted() {
+		t.Errorf("restored event %s: got %+v, %v; want it live", keep, e, ok)
+	}
+	if _, ok := repo.Get(purge); ok {
+		t.Errorf("expired event %s was not purged", purge)
+	}
+	if h := g.history.List(""); len(h) != 1 || h[0].ID != purge || h[0].Verb != "PURGE" {
+		t.Errorf("history = %+v, want one PURGE of %s", h, purge)
+	}
+}
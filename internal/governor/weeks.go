@@ -0,0 +1,118 @@
+package governor
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeekSet selects the teaching weeks a slot runs in.
+type WeekSet struct {
+	Parity int   // 0 = any, 1 = odd weeks, 2 = even weeks
+	List   []int // explicit week numbers; empty = no restriction
+}
+
+// ParseWeekSet parses a CSV weeks cell: empty/all, odd, even, or a list
+// such as 1,3,5-9.
+func ParseWeekSet(s string) (WeekSet, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "all", "*":
+		return WeekSet{}, nil
+	case "odd":
+		return WeekSet{Parity: 1}, nil
+	case "even":
+		return WeekSet{Parity: 2}, nil
+	}
+	var w WeekSet
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || a < 1 {
+			return WeekSet{}, fmt.Errorf("weeks: bad week %q", part)
+		}
+		b := a
+		if isRange {
+			b, err = strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil || b < a {
+				return WeekSet{}, fmt.Errorf("weeks: bad range %q", part)
+			}
+		}
+		for n := a; n <= b; n++ {
+			if !slices.Contains(w.List, n) {
+				w.List = append(w.List, n)
+			}
+		}
+	}
+	slices.Sort(w.List)
+	return w, nil
+}
+
+// Has reports whether week n is selected.
+func (w WeekSet) Has(n int) bool {
+	switch w.Parity {
+	case 1:
+		return n%2 == 1
+	case 2:
+		return n%2 == 0
+	}
+	return len(w.List) == 0 || slices.Contains(w.List, n)
+}
+
+func (w WeekSet) String() string {
+	switch w.Parity {
+	case 1:
+		return "odd"
+	case 2:
+		return "even"
+	}
+	parts := make([]string, len(w.List))
+	for i, n := range w.List {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Semester bounds the weekly schedule in time and numbers its weeks.
+// A zero Start numbers weeks by ISO week; a zero End is open-ended.
+type Semester struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether day falls within the semester.
+func (s Semester) Contains(day time.Time) bool {
+	day = startOfDay(day)
+	if !s.Start.IsZero() && day.Before(startOfDay(s.Start)) {
+		return false
+	}
+	if !s.End.IsZero() && day.After(startOfDay(s.End)) {
+		return false
+	}
+	return true
+}
+
+// Week returns day's teaching week number: 1 for the week containing
+// Start (weeks begin on first). If Start is unset it is the ISO number of
+// the Monday in day's week, so every day of a week gets the same number
+// whatever first is.
+func (s Semester) Week(day time.Time, first time.Weekday) int {
+	if s.Start.IsZero() {
+		monday := startOfWeek(day, first).AddDate(0, 0, (int(time.Monday)-int(first)+7)%7)
+		_, w := monday.ISOWeek()
+		return w
+	}
+	a, b := startOfWeek(s.Start, first), startOfWeek(day, first)
+	days := int(b.Sub(a).Round(24*time.Hour) / (24 * time.Hour))
+	n := days / 7
+	if days < 0 && days%7 != 0 {
+		n-- // floor, not truncation toward zero
+	}
+	if n < 0 {
+		return n // before the semester: the week before week 1 is -1; there is no week 0
+	}
+	return n + 1
+}
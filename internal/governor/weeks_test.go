@@ -0,0 +1,50 @@
+package governor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSemesterWeekWithoutStart(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 12, 0, 0, 0, time.Local) }
+	tests := []struct {
+		first time.Weekday
+		day   time.Time
+		want  int
+	}{
+		{time.Monday, day(9), 11},  // Mon
+		{time.Monday, day(15), 11}, // Sun ends the ISO week
+		{time.Monday, day(16), 12},
+		{time.Sunday, day(8), 11}, // Sun starts the week of Mon 9th
+		{time.Sunday, day(14), 11},
+		{time.Sunday, day(15), 12},
+		{time.Saturday, day(7), 11},
+	}
+	for _, tt := range tests {
+		if got := (Semester{}).Week(tt.day, tt.first); got != tt.want {
+			t.Errorf("Week(%s, first=%s) = %d, want %d", tt.day.Format("Mon 2006.01.02"), tt.first, got, tt.want)
+		}
+	}
+}
+
+func TestSemesterWeekFromStart(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.Local) }
+	sem := Semester{Start: day(time.February, 11)} // a Wednesday
+	tests := []struct {
+		day  time.Time
+		want int
+	}{
+		{day(time.February, 9), 1}, // Monday of the start week
+		{day(time.February, 11), 1},
+		{day(time.February, 15), 1},
+		{day(time.February, 16), 2},
+		{day(time.February, 8), -1}, // the week before
+		{day(time.February, 2), -1},
+		{day(time.February, 1), -2},
+	}
+	for _, tt := range tests {
+		if got := sem.Week(tt.day, time.Monday); got != tt.want {
+			t.Errorf("Week(%s) = %d, want %d", tt.day.Format("2006.01.02"), got, tt.want)
+		}
+	}
+}
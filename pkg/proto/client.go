@@ -2,10 +2,13 @@ package proto
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -35,14 +38,39 @@ func WithInbox(size int) Option {
 	return func(c *Client) { c.inbox = make(chan Message, size) }
 }
 
+// WithPeerWire pins the wire version used when sending to nodeID.
+// Peers default to WireV2 unless negotiated (see WithWireNegotiation);
+// use WireV1 for nodes that don't unescape args.
+func WithPeerWire(nodeID string, v WireVersion) Option {
+	return func(c *Client) { c.peerWire[strings.ToUpper(nodeID)] = v }
+}
+
+// WithCallTimeout sets how long Call waits for a reply when the context
+// passed to it has no deadline of its own. Zero means wait indefinitely.
+func WithCallTimeout(d time.Duration) Option {
+	return func(c *Client) { c.callTimeout = d }
+}
+
+// ErrClosed is returned by Call when the client is closed while waiting.
+var ErrClosed = errors.New("client closed")
+
 type Client struct {
 	nodeID string
 	url    string
 
 	reconnectInterval time.Duration
 	dialTimeout       time.Duration
+	callTimeout       time.Duration
 	log               *log.Logger
 	onConnect         func(*Client)
+	wireProbe         time.Duration
+	wireRetry         time.Duration
+
+	// Wire version per peer: pinned by WithPeerWire or negotiated.
+	peerWire    map[string]WireVersion
+	wireRecheck map[string]time.Time     // peers taken for WireV1 by silence: when to probe again
+	probing     map[string]chan struct{} // peers with a probe in flight
+	wireMu   sync.Mutex
 
 	conn   *websocket.Conn
 	connMu sync.Mutex
@@ -55,6 +83,11 @@ type Client struct {
 	// Nil unless WithInbox is used.
 	inbox chan Message
 
+	// In-flight Calls keyed by correlation token.
+	pending   map[string]pendingCall
+	pendingMu sync.Mutex
+	seq       atomic.Uint64
+
 	done chan struct{}
 	wg   sync.WaitGroup
 }
@@ -66,8 +99,14 @@ func New(nodeID, url string, opts ...Option) *Client {
 		url:               url,
 		reconnectInterval: 3 * time.Second,
 		dialTimeout:       5 * time.Second,
+		callTimeout:       10 * time.Second,
 		log:               log.Default(),
 		handlers:          make(map[string]HandlerFunc),
+		pending:           make(map[string]pendingCall),
+		wireRetry:         defaultWireRetry,
+		peerWire:          make(map[string]WireVersion),
+		wireRecheck:       make(map[string]time.Time),
+		probing:           make(map[string]chan struct{}),
 		done:              make(chan struct{}),
 	}
 	for _, o := range opts {
@@ -133,8 +172,88 @@ func (c *Client) Close() error {
 //	c.Send("VERTEX", "LAMP", "ON")
 //	c.Send("ACHTUNG", "NEW", "TIMER", "qwe", "10s")
 func (c *Client) Send(to, verb, noun string, args ...string) error {
-	wire := Encode(to, verb, noun, c.nodeID, args...)
-	return c.writeRaw(wire)
+	return c.sendMessage(Message{To: to, Verb: verb, Noun: noun, Args: args})
+}
+
+// Call sends a request and blocks until the matching reply arrives, the
+// context is done, the call timeout expires or the client is closed.
+// The request carries a correlation token the peer must echo back;
+// peers that don't echo tokens make Call time out.
+//
+//	msg, err := c.Call(ctx, "GOVERNOR", "GET", "UPTIME")
+//	// msg.Verb == "OK", msg.Args == ["1h2m3s"]
+func (c *Client) Call(ctx context.Context, to, verb, noun string, args ...string) (Message, error) {
+	if _, ok := ctx.Deadline(); !ok && c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.callTimeout)
+		defer cancel()
+	}
+
+	id := strconv.FormatUint(c.seq.Add(1), 10)
+	ch := make(chan Message, 1)
+	c.pendingMu.Lock()
+	c.pending[id] = pendingCall{to: strings.ToUpper(to), ch: ch}
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	msg := Message{To: to, Verb: verb, Noun: noun, Args: args, ID: id}
+	if err := c.sendMessage(msg); err != nil {
+		return Message{}, err
+	}
+
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-ctx.Done():
+		return Message{}, fmt.Errorf("call %s %s %s: %w", to, verb, noun, ctx.Err())
+	case <-c.done:
+		return Message{}, ErrClosed
+	}
+}
+
+type pendingCall struct {
+	to string
+	ch chan Message
+}
+
+// resolve hands msg to the Call waiting on its correlation token.
+// Returns false if msg is not a reply to one of our Calls; requests from
+// the peer are never taken as replies, even if their token matches.
+func (c *Client) resolve(msg Message) bool {
+	if msg.ID == "" || !IsReply(msg.Verb) {
+		return false
+	}
+	c.pendingMu.Lock()
+	p, ok := c.pending[msg.ID]
+	if ok && p.to == strings.ToUpper(msg.From) {
+		delete(c.pending, msg.ID)
+	} else {
+		ok = false
+	}
+	c.pendingMu.Unlock()
+	if ok {
+		p.ch <- msg
+	}
+	return ok
+}
+
+func (c *Client) sendMessage(m Message) error {
+	m.From = c.nodeID
+	return c.writeRaw(m.Format(c.wireFor(m.To)))
+}
+
+// wireFor returns the wire version to use when sending to nodeID.
+func (c *Client) wireFor(nodeID string) WireVersion {
+	c.wireMu.Lock()
+	defer c.wireMu.Unlock()
+	if v, ok := c.peerWire[strings.ToUpper(nodeID)]; ok {
+		return v
+	}
+	return WireV2
 }
 
 // SendRaw writes an already-encoded wire string. Use Send() when possible.
@@ -246,6 +365,15 @@ func (c *Client) tryReconnect() bool {
 }
 
 func (c *Client) dispatch(msg Message) {
+	// Replies to in-flight Calls go straight to the caller.
+	if c.resolve(msg) {
+		return
+	}
+	// Wire probes are answered here; they are protocol, not application.
+	if c.answerWire(msg) {
+		return
+	}
+
 	// Push to inbox (non-blocking) for event-loop consumers.
 	if c.inbox != nil {
 		select {
@@ -265,7 +393,19 @@ func (c *Client) dispatch(msg Message) {
 	}
 	c.handlerMu.RUnlock()
 
-	if ok {
-		go fn(&Request{Msg: msg, client: c})
+	if !ok {
+		return
+	}
+	req := &Request{Msg: msg, client: c}
+	if c.needsProbe(msg) {
+		// Learn the peer's wire version before the handler replies to it.
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), c.wireProbe)
+			c.NegotiateWire(ctx, msg.From)
+			cancel()
+			fn(req)
+		}()
+		return
 	}
+	go fn(req)
 }
@@ -0,0 +1,27 @@
+package proto
+
+import "testing"
+
+func TestResolveOnlyReplies(t *testing.T) {
+	c := New("US", "ws://localhost:0")
+	ch := make(chan Message, 1)
+	c.pending["3"] = pendingCall{to: "PEER", ch: ch}
+
+	// The peer's own request with a clashing token goes to the handlers.
+	if c.resolve(Message{To: "US", Verb: "GET", Noun: "UPTIME", From: "PEER", ID: "3"}) {
+		t.Fatal("request from peer resolved a pending Call")
+	}
+	// A reply from another node doesn't match either.
+	if c.resolve(Message{To: "US", Verb: "OK", Noun: "UPTIME", From: "OTHER", ID: "3"}) {
+		t.Fatal("reply from another node resolved the Call")
+	}
+	if !c.resolve(Message{To: "US", Verb: "OK", Noun: "UPTIME", Args: []string{"1h"}, From: "peer", ID: "3"}) {
+		t.Fatal("reply did not resolve the Call")
+	}
+	if got := <-ch; got.Verb != "OK" || got.Args[0] != "1h" {
+		t.Fatalf("Call got %+v", got)
+	}
+	if _, ok := c.pending["3"]; ok {
+		t.Fatal("resolved Call still pending")
+	}
+}
@@ -0,0 +1,117 @@
+package proto
+
+import "strings"
+
+// Escaping (wire v2):
+//
+// Args may contain any UTF-8 text. Message.String percent-escapes the
+// characters that would break framing and Parse reverses it:
+//   %  -> %25    :  -> %3A    \n -> %0A    \r -> %0D
+//
+// Args that are themselves records (slots, events) join their fields with
+// FieldSep. JoinFields escapes each field first, so a field may contain "|":
+//   |  -> %7C    %  -> %25
+// Colons inside fields are left to the arg layer, so a record reads
+// naturally on the wire:  Mon|10.45|12.10|Room 3%3A14|...
+//
+// Unknown or malformed "%" sequences are kept literally when decoding, so
+// text from peers that never escape survives unless it happens to contain
+// a valid escape.
+
+// FieldSep separates fields inside a record arg.
+const FieldSep = "|"
+
+// WireVersion selects how args are encoded for a peer.
+type WireVersion int
+
+const (
+	// WireV1 is the legacy encoding: colons are folded to dots and
+	// record-level escapes are flattened. Lossy, for peers that predate v2.
+	WireV1 WireVersion = 1
+	// WireV2 percent-escapes args and record fields. Lossless.
+	WireV2 WireVersion = 2
+)
+
+var (
+	argEscaper   = strings.NewReplacer("%", "%25", ":", "%3A", "\n", "%0A", "\r", "%0D")
+	fieldEscaper = strings.NewReplacer("%", "%25", "|", "%7C")
+	legacyArg    = strings.NewReplacer(":", ".", "\n", " ", "\r", " ", "%7C", "/", "%25", "%")
+)
+
+// EscapeArg encodes a single arg for the wire.
+func EscapeArg(s string) string { return argEscaper.Replace(s) }
+
+// UnescapeArg reverses EscapeArg.
+func UnescapeArg(s string) string { return unescape(s, "%:\n\r") }
+
+// EscapeField encodes a single record field.
+func EscapeField(s string) string { return fieldEscaper.Replace(s) }
+
+// UnescapeField reverses EscapeField.
+func UnescapeField(s string) string { return unescape(s, "%|") }
+
+// JoinFields escapes each field and joins them with FieldSep.
+//
+//	JoinFields("Mon", "10.45", "a|b")  -> "Mon|10.45|a%7Cb"
+func JoinFields(fields ...string) string {
+	esc := make([]string, len(fields))
+	for i, f := range fields {
+		esc[i] = EscapeField(f)
+	}
+	return strings.Join(esc, FieldSep)
+}
+
+// SplitFields splits a record arg on FieldSep and unescapes each field.
+func SplitFields(s string) []string {
+	parts := strings.Split(s, FieldSep)
+	for i, p := range parts {
+		parts[i] = UnescapeField(p)
+	}
+	return parts
+}
+
+func encodeArg(s string, v WireVersion) string {
+	if v == WireV1 {
+		return legacyArg.Replace(s)
+	}
+	return EscapeArg(s)
+}
+
+// unescape decodes %XX sequences whose byte is in allowed; everything else
+// is copied through unchanged.
+func unescape(s, allowed string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if c, ok := unhex(s[i+1], s[i+2]); ok && strings.IndexByte(allowed, c) >= 0 {
+				b.WriteByte(c)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func unhex(hi, lo byte) (byte, bool) {
+	h, ok1 := hexVal(hi)
+	l, ok2 := hexVal(lo)
+	return h<<4 | l, ok1 && ok2
+}
+
+func hexVal(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,87 @@
+package proto
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var roundTripTexts = []string{
+	"",
+	"plain",
+	"room 3:14",
+	"https://example.com:8443/a?b=c%20d",
+	"a|b||c",
+	"100%",
+	"%3A stays literal",
+	"%7C%25",
+	"line1\nline2\r\n",
+	"::||%%",
+	"Матан: ауд. 214 | пн",
+}
+
+func TestArgRoundTrip(t *testing.T) {
+	for _, s := range roundTripTexts {
+		enc := EscapeArg(s)
+		if strings.ContainsAny(enc, ":\n\r") {
+			t.Errorf("EscapeArg(%q) = %q still has a separator", s, enc)
+		}
+		if got := UnescapeArg(enc); got != s {
+			t.Errorf("UnescapeArg(EscapeArg(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestFieldsRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"Mon", "10.45", "12.10", "Room 3:14", "", "a|b", "100%"},
+		{"", "", ""},
+		{"only"},
+		roundTripTexts,
+	}
+	for _, fields := range records {
+		joined := JoinFields(fields...)
+		if got := SplitFields(joined); !slices.Equal(got, fields) {
+			t.Errorf("SplitFields(JoinFields(%q)) = %q", fields, got)
+		}
+		// The same record carried as a message arg.
+		m := Message{To: "US", Verb: "OK", Noun: "EVENT", Args: []string{joined, ""}, From: "GOVERNOR", ID: "7"}
+		p, err := Parse(m.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Args) != 2 || p.Args[1] != "" || !slices.Equal(SplitFields(p.Args[0]), fields) {
+			t.Errorf("record %q did not survive the wire: %q", fields, p.Args)
+		}
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	m := Message{To: "GOVERNOR", Verb: "NEW", Noun: "EVENT", Args: roundTripTexts, From: "LUCH", ID: "3"}
+	p, err := Parse(m.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.To != m.To || p.Verb != m.Verb || p.Noun != m.Noun || p.From != m.From || p.ID != m.ID {
+		t.Errorf("header %+v, want %+v", p, m)
+	}
+	if !slices.Equal(p.Args, m.Args) {
+		t.Errorf("args %q, want %q", p.Args, m.Args)
+	}
+
+	// No args, no token.
+	p, err = Parse(Message{To: "GOVERNOR", Verb: "GET", Noun: "UPTIME", From: "LUCH"}.String())
+	if err != nil || len(p.Args) != 0 || p.ID != "" {
+		t.Errorf("bare message parsed as %+v, %v", p, err)
+	}
+	if _, err := Parse("GOVERNOR:GET:UPTIME"); err == nil {
+		t.Error("3-field message parsed")
+	}
+}
+
+func TestLegacyArg(t *testing.T) {
+	m := Message{To: "OLD", Verb: "OK", Noun: "EVENT", Args: []string{"room 3:14", JoinFields("a|b", "c")}, From: "GOVERNOR"}
+	if got, want := m.Format(WireV1), "OLD:OK:EVENT:room 3.14:a/b|c:GOVERNOR"; got != want {
+		t.Errorf("v1 = %q, want %q", got, want)
+	}
+}
@@ -16,27 +16,50 @@ import (
 //   VERTEX:LED:BRIGHT:255:LUCH
 //   ACHTUNG:NEW:TIMER:qwe:10s:LUCH
 //   LUCH:OK:TIMER:qwe:ACHTUNG
+//
+// FROM may carry an optional correlation token, FROM#ID. A node that
+// receives a tokened request echoes the token in its reply, which lets
+// Client.Call match replies to requests:
+//   GOVERNOR:GET:UPTIME:LUCH#7
+//   LUCH:OK:UPTIME:1h2m3s:GOVERNOR#7
+// Messages without a token are parsed exactly as before.
 
 const Sep = ":"
 
+// IDSep separates the node ID from the correlation token in the FROM field.
+const IDSep = "#"
+
 type Message struct {
 	To   string
 	Verb string
 	Noun string
 	Args []string
 	From string
+	ID   string // correlation token; empty when the sender did not set one
 	Raw  string
 }
 
-func (m Message) String() string {
+// String encodes the message in the current (escaped) wire format.
+func (m Message) String() string { return m.Format(WireV2) }
+
+// Format encodes the message for a peer speaking wire version v.
+// Args are escaped (see escape.go); TO, VERB, NOUN and FROM are identifiers
+// and are written as-is.
+func (m Message) Format(v WireVersion) string {
+	from := m.From
+	if m.ID != "" {
+		from += IDSep + m.ID
+	}
 	parts := make([]string, 0, 4+len(m.Args))
 	parts = append(parts, m.To, m.Verb, m.Noun)
-	parts = append(parts, m.Args...)
-	parts = append(parts, m.From)
+	for _, a := range m.Args {
+		parts = append(parts, encodeArg(a, v))
+	}
+	parts = append(parts, from)
 	return strings.Join(parts, Sep)
 }
 
-// Parse decodes a raw wire string into a Message.
+// Parse decodes a raw wire string into a Message, unescaping args.
 // Returns an error if the format has fewer than 4 colon-separated fields.
 func Parse(raw string) (Message, error) {
 	parts := strings.Split(raw, Sep)
@@ -44,12 +67,18 @@ func Parse(raw string) (Message, error) {
 		return Message{}, fmt.Errorf("bad message (need at least TO:VERB:NOUN:FROM): %q", raw)
 	}
 
+	args := parts[3 : len(parts)-1]
+	for i := range args {
+		args[i] = UnescapeArg(args[i])
+	}
+	from, id, _ := strings.Cut(parts[len(parts)-1], IDSep)
 	return Message{
 		To:   parts[0],
 		Verb: parts[1],
 		Noun: parts[2],
-		Args: parts[3 : len(parts)-1],
-		From: parts[len(parts)-1],
+		Args: args,
+		From: from,
+		ID:   id,
 		Raw:  raw,
 	}, nil
 }
@@ -61,6 +90,17 @@ func Encode(to, verb, noun, from string, args ...string) string {
 	return m.String()
 }
 
+// IsReply reports whether verb answers a request (OK, ERR or PONG) rather
+// than making one. Only replies can complete a Call: tokens are per-node
+// counters, so a peer's own request may carry the same token as ours.
+func IsReply(verb string) bool {
+	switch strings.ToUpper(verb) {
+	case "OK", "ERR", "PONG":
+		return true
+	}
+	return false
+}
+
 // Request wraps an incoming message and the client that received it,
 // giving handlers a way to reply back through the concentrator.
 type Request struct {
@@ -68,10 +108,13 @@ type Request struct {
 	client *Client
 }
 
-// Reply sends a response back to the originator.
+// Reply sends a response back to the originator. The request's correlation
+// token, if any, is echoed so the sender's Call can match the reply.
 //
 //	req.Reply("OK", "LAMP")           -> SENDER:OK:LAMP:US
 //	req.Reply("OK", "TIMER", "qwe")   -> SENDER:OK:TIMER:qwe:US
 func (r *Request) Reply(verb, noun string, args ...string) error {
-	return r.client.Send(r.Msg.From, verb, noun, args...)
+	return r.client.sendMessage(Message{
+		To: r.Msg.From, Verb: verb, Noun: noun, Args: args, ID: r.Msg.ID,
+	})
 }
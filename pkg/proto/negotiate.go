@@ -0,0 +1,129 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wire negotiation:
+//
+// Every Client answers a wire probe itself, before handlers see it:
+//   US:GET:WIRE:PEER#5   ->   PEER:OK:WIRE:2:US#5
+// With WithWireNegotiation, the first request from an unknown peer makes
+// the client probe it before the handler runs, so the handler's replies
+// already use the right version. A peer that answers OK:WIRE:2 gets
+// WireV2; one that answers anything else or stays silent is taken for a
+// legacy node and gets WireV1. A silent peer may just have been slow or
+// briefly disconnected, so that verdict only holds for a few minutes; the
+// first request after that probes again. Peers pinned with WithPeerWire are
+// never probed.
+
+// WireNoun is the noun of the wire version probe.
+const WireNoun = "WIRE"
+
+// defaultWireRetry is how long a peer that ignored the probe is taken
+// for WireV1 before it is probed again.
+const defaultWireRetry = 5 * time.Minute
+
+// WithWireNegotiation probes unknown peers for their wire version,
+// waiting up to timeout for an answer.
+func WithWireNegotiation(timeout time.Duration) Option {
+	return func(c *Client) { c.wireProbe = timeout }
+}
+
+// NegotiateWire returns the wire version of nodeID, probing it with
+// GET:WIRE if it isn't known yet. Concurrent calls for one peer share a
+// single probe. If the probe can't be sent (not connected, client
+// closed) nothing is learned and the default, WireV2, is returned.
+func (c *Client) NegotiateWire(ctx context.Context, nodeID string) WireVersion {
+	id := strings.ToUpper(nodeID)
+	c.wireMu.Lock()
+	if v, ok := c.knownWireLocked(id); ok {
+		c.wireMu.Unlock()
+		return v
+	}
+	if wait, ok := c.probing[id]; ok {
+		c.wireMu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+		}
+		return c.wireFor(id)
+	}
+	done := make(chan struct{})
+	c.probing[id] = done
+	c.wireMu.Unlock()
+
+	v, learned := WireV1, true
+	reply, err := c.Call(ctx, id, "GET", WireNoun)
+	switch {
+	case err == nil:
+		if reply.Verb == "OK" && len(reply.Args) > 0 && reply.Args[0] == strconv.Itoa(int(WireV2)) {
+			v = WireV2
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		// silent peer: predates the probe
+	default:
+		learned = false
+		c.log.Printf("[%s] wire probe of %s failed: %v", c.nodeID, id, err)
+	}
+
+	c.wireMu.Lock()
+	if learned {
+		c.peerWire[id] = v
+		if err != nil {
+			c.wireRecheck[id] = time.Now().Add(c.wireRetry)
+		} else {
+			delete(c.wireRecheck, id)
+		}
+	}
+	delete(c.probing, id)
+	close(done)
+	c.wireMu.Unlock()
+	if !learned {
+		return WireV2
+	}
+	c.log.Printf("[%s] %s speaks wire v%d", c.nodeID, id, v)
+	return v
+}
+
+// answerWire replies to a GET:WIRE probe addressed to us. It returns
+// false if msg is not one.
+func (c *Client) answerWire(msg Message) bool {
+	if !strings.EqualFold(msg.Verb, "GET") || !strings.EqualFold(msg.Noun, WireNoun) ||
+		!strings.EqualFold(msg.To, c.nodeID) {
+		return false
+	}
+	reply := Message{To: msg.From, Verb: "OK", Noun: WireNoun, Args: []string{strconv.Itoa(int(WireV2))}, ID: msg.ID}
+	if err := c.sendMessage(reply); err != nil {
+		c.log.Printf("[%s] wire probe answer to %s failed: %v", c.nodeID, msg.From, err)
+	}
+	return true
+}
+
+// needsProbe reports whether msg is a request from a peer whose wire
+// version is still to be negotiated.
+func (c *Client) needsProbe(msg Message) bool {
+	if c.wireProbe <= 0 || IsReply(msg.Verb) || msg.From == "" || !strings.EqualFold(msg.To, c.nodeID) {
+		return false
+	}
+	c.wireMu.Lock()
+	_, known := c.knownWireLocked(strings.ToUpper(msg.From))
+	c.wireMu.Unlock()
+	return !known
+}
+
+// knownWireLocked returns the wire version of id if it is pinned, was
+// answered, or was guessed from silence recently enough to trust. An
+// expired guess is still returned, so sends keep using it until a new
+// probe settles the question. Caller holds c.wireMu.
+func (c *Client) knownWireLocked(id string) (WireVersion, bool) {
+	v, ok := c.peerWire[id]
+	if at, guessed := c.wireRecheck[id]; ok && guessed && !time.Now().Before(at) {
+		return v, false
+	}
+	return v, ok
+}
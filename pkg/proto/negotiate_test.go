@@ -0,0 +1,176 @@
+package proto
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testHub relays every message to all other connections, like the
+// concentrator does for nodes that filter on TO.
+func testHub(t *testing.T) string {
+	var mu sync.Mutex
+	conns := map[*websocket.Conn]bool{}
+	up := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := up.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		conns[conn] = true
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+			conn.Close()
+		}()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			for c := range conns {
+				if c != conn {
+					c.WriteMessage(websocket.TextMessage, data)
+				}
+			}
+			mu.Unlock()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func connect(t *testing.T, nodeID, url string, opts ...Option) *Client {
+	opts = append(opts, WithLogger(log.New(io.Discard, "", 0)), WithReconnect(0))
+	c := New(nodeID, url, opts...)
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// echoArgs answers GET:<noun> with OK:<noun>:<args...>.
+func echoArgs(req *Request) {
+	if req.Msg.Verb == "GET" {
+		req.Reply("OK", req.Msg.Noun, req.Msg.Args...)
+	}
+}
+
+func TestNegotiateWireV2Peer(t *testing.T) {
+	url := testHub(t)
+	gov := connect(t, "GOV", url, WithWireNegotiation(time.Second))
+	gov.Handle("*", echoArgs)
+	luch := connect(t, "LUCH", url)
+
+	reply, err := luch.Call(context.Background(), "GOV", "GET", "ECHO", "room 3:14", "a|b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Verb != "OK" || len(reply.Args) != 2 || reply.Args[0] != "room 3:14" || reply.Args[1] != "a|b" {
+		t.Fatalf("reply = %+v", reply)
+	}
+	if v := gov.wireFor("LUCH"); v != WireV2 {
+		t.Fatalf("LUCH negotiated as v%d, want v2", v)
+	}
+}
+
+func TestNegotiateWireLegacyPeer(t *testing.T) {
+	url := testHub(t)
+	gov := connect(t, "GOV", url, WithWireNegotiation(200*time.Millisecond))
+	gov.Handle("*", echoArgs)
+
+	// A legacy node: raw socket, ignores the probe, never echoes tokens.
+	old, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	if err := old.WriteMessage(websocket.TextMessage, []byte("GOV:GET:ECHO:room 3.14:OLD")); err != nil {
+		t.Fatal(err)
+	}
+	old.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var got []string
+	for {
+		_, data, err := old.ReadMessage()
+		if err != nil {
+			t.Fatalf("no reply; got %q: %v", got, err)
+		}
+		got = append(got, string(data))
+		if !strings.Contains(string(data), ":"+WireNoun+":") {
+			break
+		}
+	}
+	if len(got) != 2 || !strings.HasPrefix(got[0], "OLD:GET:WIRE:GOV#") {
+		t.Fatalf("expected a probe before the reply, got %q", got)
+	}
+	if want := "OLD:OK:ECHO:room 3.14:GOV"; got[1] != want {
+		t.Fatalf("reply = %q, want %q", got[1], want)
+	}
+	if v := gov.wireFor("OLD"); v != WireV1 {
+		t.Fatalf("OLD negotiated as v%d, want v1", v)
+	}
+}
+
+func TestNegotiateWireSilenceExpires(t *testing.T) {
+	url := testHub(t)
+	gov := connect(t, "GOV", url, WithWireNegotiation(100*time.Millisecond))
+	gov.wireRetry = 300 * time.Millisecond
+	gov.Handle("*", echoArgs)
+	req := Message{To: "GOV", Verb: "GET", Noun: "ECHO", From: "SLOW"}
+
+	// Nobody answers the first probe.
+	ctx, cancel := context.WithTimeout(context.Background(), gov.wireProbe)
+	v := gov.NegotiateWire(ctx, "SLOW")
+	cancel()
+	if v != WireV1 {
+		t.Fatalf("silent peer negotiated as v%d, want v1", v)
+	}
+	if gov.needsProbe(req) {
+		t.Fatal("silent peer probed again right away")
+	}
+	time.Sleep(gov.wireRetry)
+	if !gov.needsProbe(req) {
+		t.Fatal("silent peer never probed again")
+	}
+	if v := gov.wireFor("SLOW"); v != WireV1 {
+		t.Fatalf("before the new probe, sends use v%d, want v1", v)
+	}
+
+	// Now it is up and answers.
+	slow := connect(t, "SLOW", url)
+	if _, err := slow.Call(context.Background(), "GOV", "GET", "ECHO", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if v := gov.wireFor("SLOW"); v != WireV2 {
+		t.Fatalf("after the new probe SLOW is v%d, want v2", v)
+	}
+	if gov.needsProbe(req) {
+		t.Fatal("answered peer still due for a probe")
+	}
+}
+
+func TestPinnedPeerNotProbed(t *testing.T) {
+	c := New("GOV", "ws://localhost:0", WithWireNegotiation(time.Second), WithPeerWire("old", WireV1))
+	if c.needsProbe(Message{To: "GOV", Verb: "GET", Noun: "X", From: "OLD"}) {
+		t.Error("pinned peer would be probed")
+	}
+	if !c.needsProbe(Message{To: "GOV", Verb: "GET", Noun: "X", From: "NEW"}) {
+		t.Error("unknown peer would not be probed")
+	}
+	if c.needsProbe(Message{To: "GOV", Verb: "OK", Noun: "X", From: "NEW"}) {
+		t.Error("a reply would trigger a probe")
+	}
+}